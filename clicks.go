@@ -0,0 +1,20 @@
+package main
+
+import (
+	"github.com/zSnails/peruere/xlib"
+)
+
+// forwardClick sends a button event on a wallpaper window on to the root
+// window, where window managers listen for clicks on the desktop to open
+// their root menus or switch workspaces.
+func forwardClick(display *xlib.Display, root xlib.Window, event *xlib.XButtonEvent) error {
+	forwarded := *event
+	forwarded.Window = root
+	forwarded.Subwindow = xlib.None
+	forwarded.X, forwarded.Y = event.XRoot, event.YRoot
+	mask := xlib.ButtonPressMask
+	if event.Type() == xlib.ButtonRelease {
+		mask = xlib.ButtonReleaseMask
+	}
+	return xlib.XSendEvent(display, root, xlib.False, mask, &forwarded)
+}
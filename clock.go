@@ -0,0 +1,137 @@
+package main
+
+import (
+	"log"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gen2brain/go-mpv"
+)
+
+const (
+	// syncInterval is how often wallpapers playing the same file are brought
+	// back in step.
+	syncInterval = 2 * time.Second
+	// seekDrift is the drift in seconds past which a wallpaper starts over
+	// together with the leading one the next time that one loops, rather
+	// than only catching up by changing its speed.
+	seekDrift = 0.5
+	// loopStart is how close in seconds to the start of its file a leading
+	// wallpaper must be after restarting playback for that to be the start
+	// of a loop.
+	loopStart = 0.25
+	// nudgeDrift is the drift in seconds below which no correction is made.
+	nudgeDrift = 0.02
+	// maxNudge bounds how much the playback speed is changed to catch up.
+	maxNudge = 0.05
+)
+
+// playbackPosition returns the current position and the duration of the
+// file m plays, in seconds. ok is false if nothing with a duration plays.
+func playbackPosition(m *mpv.Mpv) (position, duration float64, ok bool) {
+	value, err := m.GetProperty("time-pos", mpv.FormatDouble)
+	if err != nil {
+		return 0, 0, false
+	}
+	position = value.(float64)
+	value, err = m.GetProperty("duration", mpv.FormatDouble)
+	if err != nil {
+		return 0, 0, false
+	}
+	duration = value.(float64)
+	return position, duration, duration > 0
+}
+
+// groups returns the wallpapers that play the same file, for each file
+// played more than once, with the one whose name sorts first leading.
+func (d *desktop) groups() map[string][]*wallpaper {
+	groups := make(map[string][]*wallpaper)
+	for _, w := range d.wallpapers {
+		groups[w.file] = append(groups[w.file], w)
+	}
+	for file, group := range groups {
+		if len(group) < 2 {
+			delete(groups, file)
+			continue
+		}
+		slices.SortFunc(group, func(a, b *wallpaper) int {
+			return strings.Compare(a.name, b.name)
+		})
+	}
+	return groups
+}
+
+// sync keeps the wallpapers that play the same file in step with the one
+// leading them. Since every file loops, the drift is measured the short way
+// around the loop, so a wallpaper that has just started over is not sent
+// back a whole loop.
+//
+// Small drifts are made up by changing the playback speed. Seeking to the
+// position of the leading wallpaper would not bring a wallpaper in step,
+// since the leader has moved on by the time the seek is done, so one that is
+// far off is marked to start over with the leader at the end of its loop
+// instead, and until then runs as fast as it may to close the gap. Lock-step
+// is thus approximate: within nudgeDrift most of the time, and after a large
+// drift, only from the next loop on.
+func (d *desktop) sync() {
+	for _, group := range d.groups() {
+		position, duration, ok := playbackPosition(group[0].m)
+		if !ok {
+			continue
+		}
+		for _, w := range group[1:] {
+			p, _, ok := playbackPosition(w.m)
+			if !ok {
+				continue
+			}
+			w.catchUp(math.Remainder(p-position, duration))
+		}
+	}
+}
+
+// restarted starts the wallpapers marked by sync over together with w if w
+// leads them and has just looped back to the start of its file.
+func (d *desktop) restarted(w *wallpaper) {
+	group := d.groups()[w.file]
+	if len(group) == 0 || group[0] != w {
+		return
+	}
+	if position, _, ok := playbackPosition(w.m); !ok || position > loopStart {
+		return
+	}
+	for _, follower := range group[1:] {
+		if !follower.rejoin {
+			continue
+		}
+		follower.rejoin = false
+		if err := follower.m.Command([]string{"seek", "0", "absolute+exact"}); err != nil {
+			log.Printf("%s: %v\n", follower.name, err)
+		}
+		follower.setSpeed(1)
+	}
+}
+
+// catchUp corrects a drift in seconds from the leading wallpaper.
+func (w *wallpaper) catchUp(drift float64) {
+	w.rejoin = math.Abs(drift) > seekDrift
+	speed := 1.0
+	if math.Abs(drift) > nudgeDrift {
+		// Make up for the drift by the next time the clocks are compared,
+		// or as much of it as the speed may change.
+		speed -= max(-maxNudge, min(drift/syncInterval.Seconds(), maxNudge))
+	}
+	w.setSpeed(speed)
+}
+
+func (w *wallpaper) setSpeed(speed float64) {
+	if speed == w.speed {
+		return
+	}
+	if err := w.m.SetProperty("speed", mpv.FormatDouble, speed); err != nil {
+		log.Printf("%s: %v\n", w.name, err)
+		return
+	}
+	w.speed = speed
+}
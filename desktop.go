@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"image"
+	"log"
+	"slices"
+	"strconv"
+
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// config holds the command line settings that decide the layouts.
+type config struct {
+	file string
+	// spec places a single window, or is nil for one window per monitor.
+	spec     *geometry.Spec
+	span     bool
+	monitors string
+	bezels   []geometry.Bezel
+	fit      geometry.FitMode
+	// scale is the scale of every monitor, or 0 to work it out for each
+	// from its physical size and then from dpi.
+	scale     geometry.Scale
+	dpi       float64
+	transform geometry.Transform
+	input     []geometry.Spec
+	mask      *image.Alpha
+	// radius is in logical pixels, like the geometries.
+	radius int
+	// outputs override the settings above for some monitors, when there is
+	// one window per monitor or they are mirrored.
+	outputs outputFlags
+	// mirror merges the monitors into one wallpaper that decodes the media
+	// once for all of them.
+	mirror bool
+}
+
+// layouts works out the wallpaper windows for a screen of the given size and
+// the monitors RandR reports, if any.
+func (c *config) layouts(display *xlib.Display, root xlib.Window, screen geometry.Rect) ([]layout, error) {
+	detected := listMonitors(display, root)
+	// Windows that are not tied to one monitor take the scale of the
+	// primary one.
+	scale := c.scaleOf(nil)
+	for i, m := range detected {
+		if m.primary || i == 0 {
+			scale = c.scaleOf(&detected[i])
+		}
+	}
+	single := func(name string, rect geometry.Rect) layout {
+		return layout{name: name, file: c.file, rect: rect, fit: c.fit, scale: scale, transform: c.transform, input: c.input, mask: c.mask, radius: scale.Device(c.radius)}
+	}
+
+	switch {
+	case c.span:
+		var rects []geometry.Rect
+		for _, m := range detected {
+			rects = append(rects, m.rect)
+		}
+		if len(rects) == 0 {
+			rects = []geometry.Rect{screen}
+		}
+		if c.monitors != "" {
+			var err error
+			rects, err = parseMonitors(c.monitors, screen, scale)
+			if err != nil {
+				return nil, err
+			}
+		}
+		span := geometry.NewSpan(rects, c.bezels)
+		l := single("span", span.Window)
+		l.span = &span
+		return []layout{l}, nil
+	case c.spec != nil:
+		return []layout{single("screen", c.spec.ResolveScaled(screen, scale))}, nil
+	case len(detected) == 0:
+		return []layout{single("screen", screen)}, nil
+	}
+
+	layouts := make([]layout, len(detected))
+	for i, m := range detected {
+		name := m.name
+		if name == "" {
+			name = strconv.Itoa(i)
+		}
+		scale := c.scaleOf(&m)
+		layouts[i] = layout{name: name, file: c.file, rect: m.rect, fit: c.fit, scale: scale, transform: c.transform, input: c.input, mask: c.mask, radius: scale.Device(c.radius)}
+		// Later flags win over earlier ones, so that a setting for a
+		// single output can follow one for the primary monitor.
+		for _, o := range c.outputs {
+			if o.matches(m, i) {
+				o.apply(&layouts[i])
+			}
+		}
+	}
+	if c.mirror {
+		return []layout{mirrorLayout(layouts)}, nil
+	}
+	return layouts, nil
+}
+
+// scaleOf returns the scale of the monitor m, or of the screen as a whole if
+// m is nil.
+func (c *config) scaleOf(m *monitor) geometry.Scale {
+	switch {
+	case c.scale > 0:
+		return c.scale
+	case m != nil:
+		return monitorScale(*m, c.dpi)
+	}
+	return geometry.ScaleForDPI(c.dpi)
+}
+
+// desktop keeps one wallpaper for each layout.
+type desktop struct {
+	ctx        context.Context
+	display    *xlib.Display
+	root       xlib.Window
+	eventMask  int64
+	events     chan wallpaperEvent
+	wallpapers map[string]*wallpaper
+	paused     pauseReason
+	// publishing reports the end of a root pixmap publish under way, and
+	// rootPaused is set when the root pixmap was last published while
+	// playback was paused, so that it still shows what the wallpapers do.
+	publishing <-chan error
+	rootPaused bool
+}
+
+func newDesktop(ctx context.Context, display *xlib.Display, root xlib.Window, eventMask int64) *desktop {
+	return &desktop{
+		ctx:        ctx,
+		display:    display,
+		root:       root,
+		eventMask:  eventMask,
+		events:     make(chan wallpaperEvent),
+		wallpapers: make(map[string]*wallpaper),
+	}
+}
+
+// arrange creates, moves and destroys wallpapers so that there is one for
+// each layout.
+func (d *desktop) arrange(layouts []layout) {
+	d.waitPublish()
+	wanted := make(map[string]bool)
+	for _, l := range layouts {
+		wanted[l.name] = true
+		if w := d.wallpapers[l.name]; w != nil {
+			switch {
+			case w.layout.equal(l):
+				continue
+			case w.canMove(l):
+				log.Printf("%s: moving to %v\n", l.name, l.rect)
+				w.move(l)
+				continue
+			}
+			// Mirrored monitors were added or removed.
+			log.Printf("%s: recreating the windows\n", l.name)
+			delete(d.wallpapers, l.name)
+			w.close()
+		}
+		log.Printf("%s: creating a window at %v\n", l.name, l.rect)
+		w, err := newWallpaper(d.ctx, d.display, d.root, l, d.eventMask, d.events)
+		if err != nil {
+			log.Printf("%s: %v\n", l.name, err)
+			continue
+		}
+		d.wallpapers[l.name] = w
+		if d.paused != 0 {
+			w.setPaused(true)
+		}
+	}
+	for name, w := range d.wallpapers {
+		if !wanted[name] {
+			log.Printf("%s: removing the window\n", name)
+			delete(d.wallpapers, name)
+			w.close()
+		}
+	}
+	xlib.XFlush(d.display)
+}
+
+// owns reports whether window is one of the wallpaper windows.
+func (d *desktop) owns(window xlib.Window) bool {
+	for _, w := range d.wallpapers {
+		if slices.Contains(w.windows, window) {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *desktop) close() {
+	d.waitPublish()
+	for name, w := range d.wallpapers {
+		delete(d.wallpapers, name)
+		w.close()
+	}
+}
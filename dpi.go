@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// xftDPI returns the Xft.dpi resource of the display, or 0 if it is unset.
+func xftDPI(display *xlib.Display) float64 {
+	resources := xlib.XResourceManagerString(display)
+	if resources == "" {
+		return 0
+	}
+	xlib.XrmInitialize()
+	database := xlib.XrmGetStringDatabase(resources)
+	if database == nil {
+		return 0
+	}
+	defer xlib.XrmDestroyDatabase(database)
+
+	value, ok := xlib.XrmGetResource(database, "Xft.dpi", "Xft.Dpi")
+	if !ok {
+		return 0
+	}
+	dpi, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0
+	}
+	return dpi
+}
+
+// parseScale parses the -scale flag, which is either a positive factor or
+// "auto", returned as 0, to work the scale out for each monitor.
+func parseScale(value string) (geometry.Scale, error) {
+	if value == "auto" {
+		return 0, nil
+	}
+	scale, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if !(scale > 0) || math.IsInf(scale, 0) {
+		return 0, fmt.Errorf("scale %v is not a positive number", value)
+	}
+	return geometry.Scale(scale), nil
+}
+
+// monitorScale returns the scale of a monitor from its physical size, or the
+// scale for fallbackDPI when the monitor does not report one.
+func monitorScale(m monitor, fallbackDPI float64) geometry.Scale {
+	dpi := geometry.PhysicalDPI(m.rect.Width, m.mmWidth)
+	if dpi <= 0 {
+		return geometry.ScaleForDPI(fallbackDPI)
+	}
+	// Physical sizes are often approximate, so snap to the quarter steps
+	// desktops usually scale by.
+	return geometry.Scale(max(math.Round(float64(geometry.ScaleForDPI(dpi))*4)/4, 1))
+}
@@ -0,0 +1,124 @@
+// Package ewmh reads and writes the Extended Window Manager Hints and the
+// few ICCCM and Motif hints that go with them, encoding each property in the
+// type and format window managers expect. The setters do not wait for the X
+// server, so their errors go to the error handler.
+package ewmh
+
+import (
+	"github.com/zSnails/peruere/xlib"
+)
+
+// Window types, for SetWindowType.
+const (
+	WindowTypeDesktop = "_NET_WM_WINDOW_TYPE_DESKTOP"
+	WindowTypeDock    = "_NET_WM_WINDOW_TYPE_DOCK"
+	WindowTypeNormal  = "_NET_WM_WINDOW_TYPE_NORMAL"
+)
+
+// Window states, for SetWMState.
+const (
+	StateAbove       = "_NET_WM_STATE_ABOVE"
+	StateBelow       = "_NET_WM_STATE_BELOW"
+	StateSticky      = "_NET_WM_STATE_STICKY"
+	StateSkipTaskbar = "_NET_WM_STATE_SKIP_TASKBAR"
+	StateSkipPager   = "_NET_WM_STATE_SKIP_PAGER"
+)
+
+// AllDesktops is the desktop of windows shown on every desktop.
+const AllDesktops = 0xFFFFFFFF
+
+func atom(display *xlib.Display, name string) xlib.Atom {
+	return xlib.XInternAtom(display, name, xlib.False)
+}
+
+func setAtoms(display *xlib.Display, window xlib.Window, property string, names []string) {
+	values := make([]uint64, len(names))
+	for i, name := range names {
+		values[i] = uint64(atom(display, name))
+	}
+	xlib.SetLongList(display, window, atom(display, property), xlib.XA_ATOM, xlib.PropModeReplace, values)
+}
+
+func getAtoms(display *xlib.Display, window xlib.Window, property string) ([]string, error) {
+	atoms, err := xlib.GetAtomList(display, window, atom(display, property))
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, len(atoms))
+	for i, a := range atoms {
+		names[i] = xlib.XGetAtomName(display, a)
+	}
+	return names, nil
+}
+
+func setCardinal(display *xlib.Display, window xlib.Window, property string, value uint32) {
+	xlib.SetLongList(display, window, atom(display, property), xlib.XA_CARDINAL, xlib.PropModeReplace, []uint64{uint64(value)})
+}
+
+func getCardinal(display *xlib.Display, window xlib.Window, property string) (uint32, error) {
+	values, err := xlib.GetCardinalList(display, window, atom(display, property))
+	if err != nil {
+		return 0, err
+	}
+	if len(values) == 0 {
+		return 0, xlib.ErrNoProperty
+	}
+	return values[0], nil
+}
+
+// SetWindowType sets _NET_WM_WINDOW_TYPE to the given types, most preferred
+// first.
+func SetWindowType(display *xlib.Display, window xlib.Window, types ...string) {
+	setAtoms(display, window, "_NET_WM_WINDOW_TYPE", types)
+}
+
+func GetWindowType(display *xlib.Display, window xlib.Window) ([]string, error) {
+	return getAtoms(display, window, "_NET_WM_WINDOW_TYPE")
+}
+
+// SetWMState sets _NET_WM_STATE to the given states. Window managers only
+// read it when the window is mapped; later changes have to be asked for with
+// a client message instead.
+func SetWMState(display *xlib.Display, window xlib.Window, states ...string) {
+	setAtoms(display, window, "_NET_WM_STATE", states)
+}
+
+func GetWMState(display *xlib.Display, window xlib.Window) ([]string, error) {
+	return getAtoms(display, window, "_NET_WM_STATE")
+}
+
+// SetDesktop sets _NET_WM_DESKTOP, the index of the desktop the window is on,
+// or AllDesktops.
+func SetDesktop(display *xlib.Display, window xlib.Window, desktop uint32) {
+	setCardinal(display, window, "_NET_WM_DESKTOP", desktop)
+}
+
+func GetDesktop(display *xlib.Display, window xlib.Window) (uint32, error) {
+	return getCardinal(display, window, "_NET_WM_DESKTOP")
+}
+
+// SetPID sets _NET_WM_PID. It is only meaningful together with
+// WM_CLIENT_MACHINE, which XSetWMProperties sets.
+func SetPID(display *xlib.Display, window xlib.Window, pid int) {
+	setCardinal(display, window, "_NET_WM_PID", uint32(pid))
+}
+
+func GetPID(display *xlib.Display, window xlib.Window) (int, error) {
+	pid, err := getCardinal(display, window, "_NET_WM_PID")
+	return int(pid), err
+}
+
+// SetWMName sets _NET_WM_NAME, which unlike WM_NAME is always UTF-8.
+func SetWMName(display *xlib.Display, window xlib.Window, name string) {
+	xlib.SetBytes(display, window, atom(display, "_NET_WM_NAME"), atom(display, "UTF8_STRING"), xlib.PropModeReplace, []byte(name))
+}
+
+func GetWMName(display *xlib.Display, window xlib.Window) (string, error) {
+	return xlib.GetUTF8String(display, window, atom(display, "_NET_WM_NAME"))
+}
+
+// SetLayer sets the GNOME _WIN_LAYER hint, which older window managers use
+// instead of _NET_WM_STATE_BELOW. Layer 0 is the desktop.
+func SetLayer(display *xlib.Display, window xlib.Window, layer uint32) {
+	setCardinal(display, window, "_WIN_LAYER", layer)
+}
@@ -0,0 +1,58 @@
+package ewmh
+
+import (
+	"fmt"
+
+	"github.com/zSnails/peruere/xlib"
+)
+
+// Bits of MotifHints.Flags telling which of the other fields are set.
+const (
+	MotifHintsFunctions   = 1 << 0
+	MotifHintsDecorations = 1 << 1
+	MotifHintsInputMode   = 1 << 2
+	MotifHintsStatus      = 1 << 3
+)
+
+// MotifHints is the _MOTIF_WM_HINTS property, which most window managers
+// still read to decide whether to decorate a window.
+type MotifHints struct {
+	Flags       uint32
+	Functions   uint32
+	Decorations uint32
+	InputMode   int32
+	Status      uint32
+}
+
+// NoDecorations asks for a window without borders or a title bar.
+var NoDecorations = MotifHints{Flags: MotifHintsDecorations}
+
+func SetMotifHints(display *xlib.Display, window xlib.Window, hints MotifHints) {
+	property := atom(display, "_MOTIF_WM_HINTS")
+	values := []uint64{
+		uint64(hints.Flags),
+		uint64(hints.Functions),
+		uint64(hints.Decorations),
+		uint64(uint32(hints.InputMode)),
+		uint64(hints.Status),
+	}
+	xlib.SetLongList(display, window, property, property, xlib.PropModeReplace, values)
+}
+
+func GetMotifHints(display *xlib.Display, window xlib.Window) (MotifHints, error) {
+	property := atom(display, "_MOTIF_WM_HINTS")
+	values, err := xlib.GetLongList(display, window, property, property)
+	if err != nil {
+		return MotifHints{}, err
+	}
+	if len(values) < 5 {
+		return MotifHints{}, fmt.Errorf("ewmh: _MOTIF_WM_HINTS has %d items, want 5", len(values))
+	}
+	return MotifHints{
+		Flags:       uint32(values[0]),
+		Functions:   uint32(values[1]),
+		Decorations: uint32(values[2]),
+		InputMode:   int32(uint32(values[3])),
+		Status:      uint32(values[4]),
+	}, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+)
+
+// mediaSize returns the display size of the video currently loaded in m, as
+// decoded and before any filter.
+func mediaSize(m *mpv.Mpv) (geometry.Rect, error) {
+	width, err := m.GetProperty("video-params/dw", mpv.FormatInt64)
+	if err != nil {
+		return geometry.Rect{}, err
+	}
+	height, err := m.GetProperty("video-params/dh", mpv.FormatInt64)
+	if err != nil {
+		return geometry.Rect{}, err
+	}
+	return geometry.Rect{Width: uint(width.(int64)), Height: uint(height.(int64))}, nil
+}
+
+// videoProperties are the mpv properties that applyFit and applySpan manage.
+// They are always set together so that switching between modes leaves no
+// stale values behind.
+type videoProperties struct {
+	keepAspect bool
+	zoom       float64
+	rotate     int
+	filter     string
+}
+
+func (v videoProperties) apply(m *mpv.Mpv) error {
+	if err := m.SetProperty("keepaspect", mpv.FormatFlag, v.keepAspect); err != nil {
+		return err
+	}
+	if err := m.SetPropertyString("panscan", "0"); err != nil {
+		return err
+	}
+	if err := m.SetProperty("video-zoom", mpv.FormatDouble, v.zoom); err != nil {
+		return err
+	}
+	if err := m.SetProperty("video-rotate", mpv.FormatInt64, v.rotate); err != nil {
+		return err
+	}
+	return m.SetPropertyString("vf", v.filter)
+}
+
+// applyFit sets the mpv properties that place media of the source size into
+// the target window according to mode, after applying transform to it.
+func applyFit(m *mpv.Mpv, source, target geometry.Rect, mode geometry.FitMode, scale geometry.Scale, transform geometry.Transform) error {
+	source = transform.Size(source)
+	p := geometry.FitScaled(source, target, mode, scale)
+	contain := geometry.Fit(source, target, geometry.Contain)
+
+	v := videoProperties{keepAspect: true}
+	switch mode {
+	case geometry.Stretch:
+		v.keepAspect = false
+	case geometry.Tile:
+		v.filter = "lavfi=[" + transformGraph(transform) + tileGraph(p, target) + "]"
+	default:
+		// mpv zooms relative to the contained size and keeps the video
+		// centered, which is where every other mode places it.
+		v.zoom = math.Log2(p.ScaleX / contain.ScaleX)
+	}
+	if mode != geometry.Tile {
+		// mpv rotates after the filters run, which matches the order in
+		// which a transform flips and then rotates.
+		v.rotate = transform.Rotation
+		if transform.Flip {
+			v.filter = "hflip"
+		}
+	}
+
+	return v.apply(m)
+}
+
+// transformGraph returns the libavfilter steps, each followed by a comma,
+// that apply transform to the video. Graphs that lay out pixels themselves
+// use it instead of video-rotate, which only runs after them.
+func transformGraph(transform geometry.Transform) string {
+	var graph strings.Builder
+	if transform.Flip {
+		graph.WriteString("hflip,")
+	}
+	switch transform.Rotation {
+	case 90:
+		graph.WriteString("transpose=clock,")
+	case 180:
+		graph.WriteString("hflip,vflip,")
+	case 270:
+		graph.WriteString("transpose=cclock,")
+	}
+	return graph.String()
+}
+
+// tileGraph builds a libavfilter graph that scales the video to its tile
+// size, repeats it until it covers p.Columns by p.Rows tiles and crops the
+// mosaic to the target size. Each step stacks the mosaic next to a copy of
+// itself, doubling it, so that the graph grows with the logarithm of the
+// number of tiles and the mosaic stays within twice the target size.
+func tileGraph(p geometry.Placement, target geometry.Rect) string {
+	var graph strings.Builder
+	fmt.Fprintf(&graph, "scale=%d:%d,", p.Content.Width, p.Content.Height)
+	step := 0
+	double := func(n int, filter string) {
+		for covered := 1; covered < n; covered *= 2 {
+			fmt.Fprintf(&graph, "split[a%d][b%d];[a%d][b%d]%s,", step, step, step, step, filter)
+			step++
+		}
+	}
+	double(p.Columns, "hstack")
+	double(p.Rows, "vstack")
+	fmt.Fprintf(&graph, "crop=%d:%d:0:0", target.Width, target.Height)
+	return graph.String()
+}
@@ -0,0 +1,69 @@
+package geometry
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMissingSize means a width or height was expected but not found, as
+	// in "1920x".
+	ErrMissingSize = errors.New("missing size")
+	// ErrMissingOffset means a sign was not followed by an offset, as in
+	// "+10-".
+	ErrMissingOffset = errors.New("missing offset")
+	// ErrFractionalPixels means a pixel value had a fractional part, which
+	// only percentages may have.
+	ErrFractionalPixels = errors.New("pixel values must be whole numbers")
+	// ErrOutOfRange means a number does not fit in the X11 coordinate range.
+	ErrOutOfRange = errors.New("value out of range")
+	// ErrUnexpected means a character that cannot start the next part of a
+	// geometry, or trailing input after a complete one.
+	ErrUnexpected = errors.New("unexpected character")
+)
+
+// Field names a component of a geometry specification.
+type Field int
+
+const (
+	FieldNone Field = iota
+	FieldWidth
+	FieldHeight
+	FieldX
+	FieldY
+)
+
+var fieldNames = [...]string{
+	FieldNone:   "none",
+	FieldWidth:  "width",
+	FieldHeight: "height",
+	FieldX:      "x offset",
+	FieldY:      "y offset",
+}
+
+func (f Field) String() string {
+	if f < 0 || int(f) >= len(fieldNames) {
+		return fmt.Sprintf("Field(%d)", int(f))
+	}
+	return fieldNames[f]
+}
+
+// ParseError describes a geometry specification that could not be parsed.
+// Err is one of the sentinel errors of this package.
+type ParseError struct {
+	Input  string
+	Offset int
+	Field  Field
+	Err    error
+}
+
+func (e *ParseError) Error() string {
+	if e.Field == FieldNone {
+		return fmt.Sprintf("invalid geometry %q at offset %d: %v", e.Input, e.Offset, e.Err)
+	}
+	return fmt.Sprintf("invalid geometry %q at offset %d, in the %v: %v", e.Input, e.Offset, e.Field, e.Err)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,75 @@
+package geometry
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		input  string
+		offset int
+		field  Field
+		err    error
+	}{
+		{"1920x", 5, FieldHeight, ErrMissingSize},
+		{"x", 1, FieldHeight, ErrMissingSize},
+		{"+", 1, FieldX, ErrMissingOffset},
+		{"100x100+10-", 11, FieldY, ErrMissingOffset},
+		{"10.5%x10.5", 8, FieldHeight, ErrFractionalPixels},
+		{"99999999999x1", 0, FieldWidth, ErrOutOfRange},
+		{"1920x1080+0+0+0", 13, FieldNone, ErrUnexpected},
+		{"10y10", 2, FieldHeight, ErrUnexpected},
+		{"==", 1, FieldWidth, ErrMissingSize},
+	}
+
+	for _, test := range tests {
+		_, err := ParseSpec(test.input)
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Fatalf("%q: got %v, want a *ParseError", test.input, err)
+		}
+		if parseErr.Input != test.input || parseErr.Offset != test.offset || parseErr.Field != test.field || !errors.Is(err, test.err) {
+			t.Fatalf("%q: got %+v, want offset %d in the %v: %v", test.input, parseErr, test.offset, test.field, test.err)
+		}
+	}
+}
+
+func FuzzParseSpec(f *testing.F) {
+	for _, seed := range []string{
+		"", "=", "1920x1080+0+0", "-0-0", "50%x100%+50%+0", "100%x100%-0+30",
+		"33.5%x480-12.25%-0", "x", "+", "1920x", "10.5", "99999999999", "=+1-2",
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, input string) {
+		spec, err := ParseSpec(input)
+		if err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("%q: got %T, want a *ParseError", input, err)
+			}
+			if parseErr.Offset < 0 || parseErr.Offset > len(input) {
+				t.Fatalf("%q: offset %d out of bounds", input, parseErr.Offset)
+			}
+			return
+		}
+
+		again, err := ParseSpec(spec.String())
+		if err != nil {
+			t.Fatalf("%q formatted as %q does not parse: %v", input, spec.String(), err)
+		}
+		if again != spec {
+			t.Fatalf("%q formatted as %q parses to %+v, want %+v", input, spec.String(), again, spec)
+		}
+		spec.Resolve(Rect{Width: 1920, Height: 1080})
+
+		if _, _, _, _, _, err := ParseGeometry(input); err != nil {
+			var parseErr *ParseError
+			if !errors.As(err, &parseErr) {
+				t.Fatalf("%q: got %T, want a *ParseError", input, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,124 @@
+package geometry
+
+import (
+	"fmt"
+	"math"
+)
+
+// FitMode tells how media is placed into a window of a different size.
+type FitMode int
+
+const (
+	// Contain scales the media to fit inside the window, keeping its aspect
+	// ratio and leaving bars on two sides if the ratios differ.
+	Contain FitMode = iota
+	// Cover scales the media to fill the window, keeping its aspect ratio and
+	// cropping whatever does not fit.
+	Cover
+	// Stretch scales each axis independently to fill the window exactly.
+	Stretch
+	// Center keeps the media at its native size, centered in the window.
+	Center
+	// Tile repeats the media at its native size from the top left corner.
+	Tile
+)
+
+var fitModeNames = [...]string{
+	Contain: "contain",
+	Cover:   "cover",
+	Stretch: "stretch",
+	Center:  "center",
+	Tile:    "tile",
+}
+
+// ParseFitMode returns the FitMode with the given name.
+func ParseFitMode(name string) (FitMode, error) {
+	for mode, modeName := range fitModeNames {
+		if name == modeName {
+			return FitMode(mode), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown fit mode %q", name)
+}
+
+func (m FitMode) String() string {
+	if m < 0 || int(m) >= len(fitModeNames) {
+		return fmt.Sprintf("FitMode(%d)", int(m))
+	}
+	return fitModeNames[m]
+}
+
+// Placement describes how media is drawn into a target window.
+type Placement struct {
+	// ScaleX and ScaleY are the factors applied to the media size.
+	ScaleX, ScaleY float64
+	// Content is where the scaled media lands, relative to the top left
+	// corner of the target. It extends past the target when the media is
+	// cropped. In Tile mode it is the first copy.
+	Content Rect
+	// Crop is the part of the media that ends up visible, in media pixels.
+	Crop Rect
+	// Columns and Rows count the copies needed to cover the target. They are
+	// both 1 except in Tile mode.
+	Columns, Rows int
+}
+
+// Fit computes how media of the source size is placed into the target size
+// using the given mode. Only the sizes of source and target are used.
+func Fit(source, target Rect, mode FitMode) Placement {
+	return FitScaled(source, target, mode, 1)
+}
+
+// FitScaled is like Fit for a target with the given scale: modes that keep
+// the media at its native size, Center and Tile, show one media pixel as one
+// logical pixel instead of one device pixel.
+func FitScaled(source, target Rect, mode FitMode, scale Scale) Placement {
+	if scale <= 0 {
+		scale = 1
+	}
+	p := Placement{ScaleX: float64(scale), ScaleY: float64(scale), Columns: 1, Rows: 1}
+	if source.Empty() || target.Empty() {
+		return p
+	}
+
+	sx := float64(target.Width) / float64(source.Width)
+	sy := float64(target.Height) / float64(source.Height)
+	switch mode {
+	case Contain:
+		p.ScaleX = min(sx, sy)
+		p.ScaleY = p.ScaleX
+	case Cover:
+		p.ScaleX = max(sx, sy)
+		p.ScaleY = p.ScaleX
+	case Stretch:
+		p.ScaleX, p.ScaleY = sx, sy
+	}
+
+	// Media scaled down to nothing still takes a pixel, which also keeps
+	// the tile count finite.
+	p.Content.Width = max(1, uint(math.Round(float64(source.Width)*p.ScaleX)))
+	p.Content.Height = max(1, uint(math.Round(float64(source.Height)*p.ScaleY)))
+	if mode == Tile {
+		p.Columns = int((target.Width + p.Content.Width - 1) / p.Content.Width)
+		p.Rows = int((target.Height + p.Content.Height - 1) / p.Content.Height)
+	} else {
+		p.Content.X = (int(target.Width) - int(p.Content.Width)) / 2
+		p.Content.Y = (int(target.Height) - int(p.Content.Height)) / 2
+	}
+
+	visible := p.Content.Intersect(Rect{Width: target.Width, Height: target.Height})
+	x0 := float64(visible.X-p.Content.X) / p.ScaleX
+	y0 := float64(visible.Y-p.Content.Y) / p.ScaleY
+	x1 := float64(visible.Right()-p.Content.X) / p.ScaleX
+	y1 := float64(visible.Bottom()-p.Content.Y) / p.ScaleY
+	p.Crop = Rect{
+		X:      int(math.Round(x0)),
+		Y:      int(math.Round(y0)),
+		Width:  uint(math.Round(x1) - math.Round(x0)),
+		Height: uint(math.Round(y1) - math.Round(y0)),
+	}
+	// Rounding the content size can make it a fraction of a media pixel
+	// larger than the media, which must not be cropped from beyond its edge.
+	p.Crop = p.Crop.Intersect(Rect{Width: source.Width, Height: source.Height})
+	return p
+}
@@ -0,0 +1,65 @@
+package geometry
+
+import "testing"
+
+func TestFit(t *testing.T) {
+	video := Rect{Width: 1920, Height: 1080}
+	ultrawide := Rect{Width: 3440, Height: 1440}
+	portrait := Rect{Width: 1080, Height: 1920}
+
+	tests := []struct {
+		mode          FitMode
+		target        Rect
+		content, crop Rect
+		columns, rows int
+	}{
+		{Contain, ultrawide, Rect{X: 440, Y: 0, Width: 2560, Height: 1440}, video, 1, 1},
+		{Cover, ultrawide, Rect{X: 0, Y: -247, Width: 3440, Height: 1935}, Rect{X: 0, Y: 138, Width: 1920, Height: 804}, 1, 1},
+		{Cover, portrait, Rect{X: -1166, Y: 0, Width: 3413, Height: 1920}, Rect{X: 656, Y: 0, Width: 607, Height: 1080}, 1, 1},
+		{Stretch, portrait, Rect{Width: 1080, Height: 1920}, video, 1, 1},
+		{Center, ultrawide, Rect{X: 760, Y: 180, Width: 1920, Height: 1080}, video, 1, 1},
+		{Center, portrait, Rect{X: -420, Y: 420, Width: 1920, Height: 1080}, Rect{X: 420, Y: 0, Width: 1080, Height: 1080}, 1, 1},
+		{Tile, ultrawide, Rect{Width: 1920, Height: 1080}, video, 2, 2},
+		{Tile, portrait, Rect{Width: 1920, Height: 1080}, Rect{Width: 1080, Height: 1080}, 1, 2},
+		{Contain, portrait, Rect{X: 0, Y: 656, Width: 1080, Height: 608}, video, 1, 1},
+	}
+
+	for _, test := range tests {
+		p := Fit(video, test.target, test.mode)
+		if p.Content != test.content || p.Crop != test.crop || p.Columns != test.columns || p.Rows != test.rows {
+			t.Fatalf("%v into %v: got content %v crop %v %dx%d, want content %v crop %v %dx%d",
+				test.mode, test.target, p.Content, p.Crop, p.Columns, p.Rows,
+				test.content, test.crop, test.columns, test.rows)
+		}
+	}
+}
+
+func TestFitScaledTiny(t *testing.T) {
+	p := FitScaled(Rect{Width: 1, Height: 1}, Rect{Width: 100, Height: 100}, Tile, 0.4)
+	want := Rect{Width: 1, Height: 1}
+	if p.Content != want || p.Crop != want || p.Columns != 100 || p.Rows != 100 {
+		t.Fatalf("got content %v crop %v %dx%d", p.Content, p.Crop, p.Columns, p.Rows)
+	}
+}
+
+func TestFitStretchScale(t *testing.T) {
+	p := Fit(Rect{Width: 100, Height: 100}, Rect{Width: 200, Height: 50}, Stretch)
+	if p.ScaleX != 2 || p.ScaleY != 0.5 {
+		t.Fatalf("got scale %vx%v", p.ScaleX, p.ScaleY)
+	}
+}
+
+func TestParseFitMode(t *testing.T) {
+	for _, mode := range []FitMode{Contain, Cover, Stretch, Center, Tile} {
+		parsed, err := ParseFitMode(mode.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != mode {
+			t.Fatalf("got %v, want %v", parsed, mode)
+		}
+	}
+	if _, err := ParseFitMode("zoom"); err == nil {
+		t.Fatal("expected an error")
+	}
+}
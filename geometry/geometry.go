@@ -2,33 +2,169 @@ package geometry
 
 import (
 	"strconv"
-	"strings"
 )
 
-func ParseGeometry(geometry string) (uint, uint, int, int, error) {
-	split := strings.FieldsFunc(geometry, func(r rune) bool {
-		return r == 'x' || r == '+'
-	})
+// Flags reports which fields of a geometry string were present, using the
+// same bit layout as the mask returned by XParseGeometry.
+type Flags uint
 
-	width, err := strconv.ParseUint(split[0], 10, 32)
+const (
+	NoValue     Flags = 0x0000
+	XValue      Flags = 0x0001
+	YValue      Flags = 0x0002
+	WidthValue  Flags = 0x0004
+	HeightValue Flags = 0x0008
+	AllValues   Flags = 0x000F
+	XNegative   Flags = 0x0010
+	YNegative   Flags = 0x0020
+)
+
+// ParseGeometry parses an X11 geometry specification of the form
+//
+//	[=][<width>{xX}<height>][{+-}<xoffset>[{+-}<yoffset>]]
+//
+// Every part is optional; the returned flags tell which ones were present.
+// A negative offset is measured from the right or bottom edge, in which case
+// x or y holds the negated distance and XNegative or YNegative is set, so
+// that "-0" can be told apart from "+0".
+func ParseGeometry(geometry string) (width, height uint, x, y int, flags Flags, err error) {
+	spec, err := parse(geometry, false)
 	if err != nil {
-		return 0, 0, 0, 0, err
+		return 0, 0, 0, 0, NoValue, err
 	}
-	height, err := strconv.ParseUint(split[1], 10, 32)
-	if err != nil {
-		return 0, 0, 0, 0, err
+	return uint(spec.Width.Value), uint(spec.Height.Value), int(spec.X.Value), int(spec.Y.Value), spec.Flags, nil
+}
+
+// Resolve fills in the fields that were missing from a parsed geometry and
+// turns offsets from the right or bottom edge into absolute ones, placing r
+// on the given screen. A missing size defaults to the whole screen and a
+// missing offset to zero.
+func Resolve(r Rect, flags Flags, screen Rect) Rect {
+	spec := Spec{
+		Width:  Px(int(r.Width)),
+		Height: Px(int(r.Height)),
+		X:      Px(r.X),
+		Y:      Px(r.Y),
+		Flags:  flags,
 	}
+	return spec.Resolve(screen)
+}
 
-	xoff, err := strconv.ParseInt(split[2], 10, 32)
-	if err != nil {
-		return 0, 0, 0, 0, err
+func parse(geometry string, percent bool) (Spec, error) {
+	var spec Spec
+	var err error
+	s := geometry
+	i := 0
+	if i < len(s) && s[i] == '=' {
+		i++
 	}
 
-	yoff, err := strconv.ParseInt(split[3], 10, 32)
-	if err != nil {
-		return 0, 0, 0, 0, err
+	if i < len(s) && s[i] != '+' && s[i] != '-' && s[i] != 'x' && s[i] != 'X' {
+		spec.Width, i, err = readLength(s, i, percent, FieldWidth)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Flags |= WidthValue
 	}
 
-	return uint(width), uint(height), int(xoff), int(yoff), nil
+	if i < len(s) && (s[i] == 'x' || s[i] == 'X') {
+		spec.Height, i, err = readLength(s, i+1, percent, FieldHeight)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Flags |= HeightValue
+	}
+
+	if i < len(s) && (s[i] == '+' || s[i] == '-') {
+		var negative bool
+		spec.X, negative, i, err = readOffset(s, i, percent, FieldX)
+		if err != nil {
+			return Spec{}, err
+		}
+		spec.Flags |= XValue
+		if negative {
+			spec.Flags |= XNegative
+		}
+
+		if i < len(s) && (s[i] == '+' || s[i] == '-') {
+			spec.Y, negative, i, err = readOffset(s, i, percent, FieldY)
+			if err != nil {
+				return Spec{}, err
+			}
+			spec.Flags |= YValue
+			if negative {
+				spec.Flags |= YNegative
+			}
+		}
+	}
+
+	if i != len(s) {
+		return Spec{}, &ParseError{Input: s, Offset: i, Field: fieldAfter(spec.Flags), Err: ErrUnexpected}
+	}
+
+	return spec, nil
+}
 
+// fieldAfter returns the field that would follow the last one present.
+func fieldAfter(flags Flags) Field {
+	switch {
+	case flags&YValue != 0:
+		return FieldNone
+	case flags&XValue != 0:
+		return FieldY
+	case flags&HeightValue != 0:
+		return FieldX
+	case flags&WidthValue != 0:
+		return FieldHeight
+	}
+	return FieldWidth
+}
+
+func readLength(s string, i int, percent bool, field Field) (Length, int, error) {
+	start := i
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if start == i {
+		missing := ErrMissingSize
+		if field == FieldX || field == FieldY {
+			missing = ErrMissingOffset
+		}
+		return Length{}, i, &ParseError{Input: s, Offset: start, Field: field, Err: missing}
+	}
+
+	digits := i
+	if percent && i < len(s) && s[i] == '.' {
+		i++
+		for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+			i++
+		}
+	}
+	if !percent || i == len(s) || s[i] != '%' {
+		if i != digits {
+			return Length{}, i, &ParseError{Input: s, Offset: digits, Field: field, Err: ErrFractionalPixels}
+		}
+		n, err := strconv.ParseUint(s[start:i], 10, 31)
+		if err != nil {
+			return Length{}, i, &ParseError{Input: s, Offset: start, Field: field, Err: ErrOutOfRange}
+		}
+		return Px(int(n)), i, nil
+	}
+	n, err := strconv.ParseFloat(s[start:i], 64)
+	if err != nil {
+		return Length{}, i, &ParseError{Input: s, Offset: start, Field: field, Err: ErrOutOfRange}
+	}
+	return Pct(n), i + 1, nil
+}
+
+func readOffset(s string, i int, percent bool, field Field) (Length, bool, int, error) {
+	negative := s[i] == '-'
+	l, i, err := readLength(s, i+1, percent, field)
+	if err != nil {
+		return Length{}, false, i, err
+	}
+	if negative {
+		l.Value = -l.Value
+	}
+	return l, negative, i, nil
 }
@@ -6,10 +6,10 @@ import (
 )
 
 func TestParseGeometry(t *testing.T) {
-	x, y, xoff, yoff, err := ParseGeometry("1920x1080+10+20")
-    if err != nil {
-        t.Fatal(err)
-    }
+	x, y, xoff, yoff, flags, err := ParseGeometry("1920x1080+10+20")
+	if err != nil {
+		t.Fatal(err)
+	}
 	fmt.Printf("x: %v\n", x)
 	fmt.Printf("y: %v\n", y)
 	fmt.Printf("xoff: %v\n", xoff)
@@ -30,4 +30,78 @@ func TestParseGeometry(t *testing.T) {
 	if yoff != 20 {
 		t.Fatal("yoff != 0")
 	}
+
+	if flags != AllValues {
+		t.Fatalf("flags = %#x, want AllValues", flags)
+	}
+}
+
+func TestParseGeometryPartial(t *testing.T) {
+	tests := []struct {
+		input         string
+		width, height uint
+		x, y          int
+		flags         Flags
+	}{
+		{"", 0, 0, 0, 0, NoValue},
+		{"=", 0, 0, 0, 0, NoValue},
+		{"1920x1080", 1920, 1080, 0, 0, WidthValue | HeightValue},
+		{"=800X600", 800, 600, 0, 0, WidthValue | HeightValue},
+		{"640", 640, 0, 0, 0, WidthValue},
+		{"x480", 0, 480, 0, 0, HeightValue},
+		{"+0+0", 0, 0, 0, 0, XValue | YValue},
+		{"+15", 0, 0, 15, 0, XValue},
+		{"-0-0", 0, 0, 0, 0, XValue | YValue | XNegative | YNegative},
+		{"100x50-10+20", 100, 50, -10, 20, AllValues | XNegative},
+		{"=100x50+10-20", 100, 50, 10, -20, AllValues | YNegative},
+	}
+
+	for _, test := range tests {
+		width, height, x, y, flags, err := ParseGeometry(test.input)
+		if err != nil {
+			t.Fatalf("%q: %v", test.input, err)
+		}
+		if width != test.width || height != test.height || x != test.x || y != test.y || flags != test.flags {
+			t.Fatalf("%q: got %dx%d%+d%+d flags %#x, want %dx%d%+d%+d flags %#x",
+				test.input, width, height, x, y, flags,
+				test.width, test.height, test.x, test.y, test.flags)
+		}
+	}
+}
+
+func TestParseGeometryInvalid(t *testing.T) {
+	for _, input := range []string{"x", "100x", "+", "+10-", "10y10", "1920x1080+0+0+0", "99999999999x1", "=="} {
+		if _, _, _, _, _, err := ParseGeometry(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	screen := Rect{Width: 2560, Height: 1440}
+	r, flags, err := ParseRect("-0-0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Resolve(r, flags, screen); got != screen {
+		t.Fatalf("got %v, want %v", got, screen)
+	}
+
+	r, flags, err = ParseRect("800x600-10-20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rect{X: 1750, Y: 820, Width: 800, Height: 600}
+	if got := Resolve(r, flags, screen); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	r, flags, err = ParseRect("+10+10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = Rect{X: 1930, Y: 10, Width: 1280, Height: 1024}
+	if got := Resolve(r, flags, Rect{X: 1920, Width: 1280, Height: 1024}); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
 }
@@ -0,0 +1,102 @@
+package geometry
+
+import "fmt"
+
+// Rect is an axis-aligned rectangle in X11 coordinates, with the origin at
+// the top left corner and y growing downwards.
+type Rect struct {
+	X, Y          int
+	Width, Height uint
+}
+
+// ParseRect parses a geometry specification into a Rect. The values are
+// stored as parsed: use Resolve to fill in missing fields and to anchor
+// negative offsets to the far edges of a screen.
+func ParseRect(geometry string) (Rect, Flags, error) {
+	width, height, x, y, flags, err := ParseGeometry(geometry)
+	if err != nil {
+		return Rect{}, NoValue, err
+	}
+	return Rect{X: x, Y: y, Width: width, Height: height}, flags, nil
+}
+
+// Right returns the x coordinate just past the right edge of r.
+func (r Rect) Right() int {
+	return r.X + int(r.Width)
+}
+
+// Bottom returns the y coordinate just past the bottom edge of r.
+func (r Rect) Bottom() int {
+	return r.Y + int(r.Height)
+}
+
+// Empty reports whether r has no area.
+func (r Rect) Empty() bool {
+	return r.Width == 0 || r.Height == 0
+}
+
+// Intersect returns the largest rectangle contained in both r and s. If they
+// do not overlap the zero Rect is returned.
+func (r Rect) Intersect(s Rect) Rect {
+	x0, y0 := max(r.X, s.X), max(r.Y, s.Y)
+	x1, y1 := min(r.Right(), s.Right()), min(r.Bottom(), s.Bottom())
+	if x0 >= x1 || y0 >= y1 {
+		return Rect{}
+	}
+	return Rect{X: x0, Y: y0, Width: uint(x1 - x0), Height: uint(y1 - y0)}
+}
+
+// Union returns the smallest rectangle that contains both r and s. Empty
+// rectangles are ignored.
+func (r Rect) Union(s Rect) Rect {
+	if r.Empty() {
+		return s
+	}
+	if s.Empty() {
+		return r
+	}
+	x0, y0 := min(r.X, s.X), min(r.Y, s.Y)
+	x1, y1 := max(r.Right(), s.Right()), max(r.Bottom(), s.Bottom())
+	return Rect{X: x0, Y: y0, Width: uint(x1 - x0), Height: uint(y1 - y0)}
+}
+
+// Contains reports whether s lies entirely within r. An empty s is contained
+// in any rectangle.
+func (r Rect) Contains(s Rect) bool {
+	if s.Empty() {
+		return true
+	}
+	return r.X <= s.X && r.Y <= s.Y && s.Right() <= r.Right() && s.Bottom() <= r.Bottom()
+}
+
+// ContainsPoint reports whether the point (x, y) lies within r.
+func (r Rect) ContainsPoint(x, y int) bool {
+	return r.X <= x && x < r.Right() && r.Y <= y && y < r.Bottom()
+}
+
+// Translate returns r moved by dx and dy.
+func (r Rect) Translate(dx, dy int) Rect {
+	r.X += dx
+	r.Y += dy
+	return r
+}
+
+// Constrain returns r moved the shortest distance needed to lie within
+// bounds, shrinking it only along the axes where it is larger than bounds.
+// Use Intersect to cut r to bounds instead.
+func (r Rect) Constrain(bounds Rect) Rect {
+	r.Width = min(r.Width, bounds.Width)
+	r.Height = min(r.Height, bounds.Height)
+	r.X = max(bounds.X, min(r.X, bounds.Right()-int(r.Width)))
+	r.Y = max(bounds.Y, min(r.Y, bounds.Bottom()-int(r.Height)))
+	return r
+}
+
+// String formats r as a geometry specification. ParseRect turns it back into
+// the same Rect, but a negative X or Y comes back with XNegative or YNegative
+// set, so that Resolve would measure it from the right or bottom edge of the
+// screen: the string is meant for showing r, not for handing an absolute
+// position left of or above the origin back to Resolve.
+func (r Rect) String() string {
+	return fmt.Sprintf("%dx%d%+d%+d", r.Width, r.Height, r.X, r.Y)
+}
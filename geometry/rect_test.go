@@ -0,0 +1,90 @@
+package geometry
+
+import "testing"
+
+func TestRectIntersect(t *testing.T) {
+	a := Rect{X: 0, Y: 0, Width: 100, Height: 100}
+	b := Rect{X: 50, Y: 25, Width: 100, Height: 100}
+	want := Rect{X: 50, Y: 25, Width: 50, Height: 75}
+	if got := a.Intersect(b); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	c := Rect{X: 100, Y: 0, Width: 10, Height: 10}
+	if got := a.Intersect(c); !got.Empty() {
+		t.Fatalf("touching rectangles should not intersect, got %v", got)
+	}
+}
+
+func TestRectUnion(t *testing.T) {
+	left := Rect{X: 0, Y: 0, Width: 1920, Height: 1080}
+	right := Rect{X: 1920, Y: 200, Width: 1280, Height: 1024}
+	want := Rect{X: 0, Y: 0, Width: 3200, Height: 1224}
+	if got := left.Union(right); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if got := left.Union(Rect{X: -50, Y: -50}); got != left {
+		t.Fatalf("empty rectangles should be ignored, got %v", got)
+	}
+}
+
+func TestRectContains(t *testing.T) {
+	r := Rect{X: 10, Y: 10, Width: 100, Height: 100}
+	if !r.Contains(Rect{X: 10, Y: 10, Width: 100, Height: 100}) {
+		t.Fatal("a rectangle should contain itself")
+	}
+	if r.Contains(Rect{X: 50, Y: 50, Width: 100, Height: 10}) {
+		t.Fatal("overflowing rectangle reported as contained")
+	}
+	if !r.ContainsPoint(10, 109) || r.ContainsPoint(110, 50) {
+		t.Fatal("ContainsPoint does not treat the far edges as exclusive")
+	}
+}
+
+func TestRectConstrain(t *testing.T) {
+	bounds := Rect{Width: 1920, Height: 1080}
+	tests := []struct {
+		r, want Rect
+	}{
+		{Rect{X: 10, Y: 10, Width: 100, Height: 100}, Rect{X: 10, Y: 10, Width: 100, Height: 100}},
+		{Rect{X: 1900, Y: -20, Width: 100, Height: 100}, Rect{X: 1820, Y: 0, Width: 100, Height: 100}},
+		{Rect{X: -100, Y: 500, Width: 2560, Height: 1440}, Rect{X: 0, Y: 0, Width: 1920, Height: 1080}},
+	}
+	for _, test := range tests {
+		if got := test.r.Constrain(bounds); got != test.want {
+			t.Fatalf("%v: got %v, want %v", test.r, got, test.want)
+		}
+	}
+}
+
+func TestRectTranslate(t *testing.T) {
+	r := Rect{X: 10, Y: 20, Width: 30, Height: 40}
+	want := Rect{X: 5, Y: 60, Width: 30, Height: 40}
+	if got := r.Translate(-5, 40); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestRectString(t *testing.T) {
+	tests := []struct {
+		r     Rect
+		flags Flags
+	}{
+		{Rect{X: 0, Y: 0, Width: 1920, Height: 1080}, AllValues},
+		{Rect{X: 1920, Y: 35, Width: 1280, Height: 1024}, AllValues},
+		{Rect{X: -10, Y: 20, Width: 1, Height: 2}, AllValues | XNegative},
+		{Rect{X: -10, Y: -20, Width: 1, Height: 2}, AllValues | XNegative | YNegative},
+	}
+	for _, test := range tests {
+		parsed, flags, err := ParseRect(test.r.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != test.r {
+			t.Fatalf("%q: got %v back", test.r.String(), parsed)
+		}
+		if flags != test.flags {
+			t.Fatalf("%q: flags = %#x, want %#x", test.r.String(), flags, test.flags)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package geometry
+
+import "math"
+
+// BaseDPI is the resolution at which one logical pixel is one device pixel.
+const BaseDPI = 96
+
+// Scale is the number of device pixels per logical pixel.
+type Scale float64
+
+// ScaleForDPI returns the scale of a monitor with the given resolution in
+// dots per inch. Unknown resolutions, zero or less, give a scale of 1.
+func ScaleForDPI(dpi float64) Scale {
+	if dpi <= 0 {
+		return 1
+	}
+	return Scale(dpi / BaseDPI)
+}
+
+// PhysicalDPI returns the resolution of a monitor that is the given number of
+// pixels and millimeters wide, or 0 if the physical size is unknown.
+func PhysicalDPI(pixels, millimeters uint) float64 {
+	if millimeters == 0 {
+		return 0
+	}
+	return float64(pixels) * 25.4 / float64(millimeters)
+}
+
+// Device converts a logical length to device pixels.
+func (s Scale) Device(n int) int {
+	return int(math.Round(float64(n) * float64(s)))
+}
+
+// Logical converts a length in device pixels to logical pixels.
+func (s Scale) Logical(n int) int {
+	return int(math.Round(float64(n) / float64(s)))
+}
+
+// DeviceRect converts a rectangle in logical pixels to device pixels.
+func (s Scale) DeviceRect(r Rect) Rect {
+	return Rect{
+		X:      s.Device(r.X),
+		Y:      s.Device(r.Y),
+		Width:  uint(s.Device(int(r.Width))),
+		Height: uint(s.Device(int(r.Height))),
+	}
+}
+
+// LogicalRect converts a rectangle in device pixels to logical pixels.
+func (s Scale) LogicalRect(r Rect) Rect {
+	return Rect{
+		X:      s.Logical(r.X),
+		Y:      s.Logical(r.Y),
+		Width:  uint(s.Logical(int(r.Width))),
+		Height: uint(s.Logical(int(r.Height))),
+	}
+}
@@ -0,0 +1,52 @@
+package geometry
+
+import "testing"
+
+func TestScaleForDPI(t *testing.T) {
+	if s := ScaleForDPI(192); s != 2 {
+		t.Fatalf("192 dpi: got %v", s)
+	}
+	if s := ScaleForDPI(0); s != 1 {
+		t.Fatalf("unknown dpi: got %v", s)
+	}
+	if dpi := PhysicalDPI(3840, 344); dpi < 283 || dpi > 284 {
+		t.Fatalf("got %v dpi for a 14 inch 4K panel", dpi)
+	}
+	if dpi := PhysicalDPI(1920, 0); dpi != 0 {
+		t.Fatalf("got %v dpi for an unknown size", dpi)
+	}
+}
+
+func TestScaleRect(t *testing.T) {
+	s := Scale(1.5)
+	logical := Rect{X: 100, Y: -10, Width: 640, Height: 480}
+	device := Rect{X: 150, Y: -15, Width: 960, Height: 720}
+	if got := s.DeviceRect(logical); got != device {
+		t.Fatalf("got %v, want %v", got, device)
+	}
+	if got := s.LogicalRect(device); got != logical {
+		t.Fatalf("got %v, want %v", got, logical)
+	}
+}
+
+func TestSpecResolveScaled(t *testing.T) {
+	spec, err := ParseSpec("640x50%-20+10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Rect{X: 2520, Y: 20, Width: 1280, Height: 1080}
+	if got := spec.ResolveScaled(Rect{Width: 3840, Height: 2160}, 2); got != want {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+}
+
+func TestFitScaled(t *testing.T) {
+	p := FitScaled(Rect{Width: 800, Height: 600}, Rect{Width: 3840, Height: 2160}, Tile, 2)
+	if p.Content != (Rect{Width: 1600, Height: 1200}) || p.Columns != 3 || p.Rows != 2 {
+		t.Fatalf("got content %v, %dx%d copies", p.Content, p.Columns, p.Rows)
+	}
+	p = FitScaled(Rect{Width: 800, Height: 600}, Rect{Width: 3840, Height: 2160}, Cover, 2)
+	if p.Content != Fit(Rect{Width: 800, Height: 600}, Rect{Width: 3840, Height: 2160}, Cover).Content {
+		t.Fatal("the scale should not change how cover fills the target")
+	}
+}
@@ -0,0 +1,134 @@
+package geometry
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Bezel is the width of the frame around the visible area of a monitor, in
+// pixels of that monitor.
+type Bezel struct {
+	Left, Right, Top, Bottom uint
+}
+
+// ParseBezel parses a bezel given as "all", "horizontal:vertical" or
+// "left:right:top:bottom".
+func ParseBezel(bezel string) (Bezel, error) {
+	parts := strings.Split(bezel, ":")
+	values := make([]uint, len(parts))
+	for i, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 31)
+		if err != nil {
+			return Bezel{}, fmt.Errorf("invalid bezel %q: %w", bezel, err)
+		}
+		values[i] = uint(n)
+	}
+	switch len(values) {
+	case 1:
+		return Bezel{values[0], values[0], values[0], values[0]}, nil
+	case 2:
+		return Bezel{values[0], values[0], values[1], values[1]}, nil
+	case 4:
+		return Bezel{values[0], values[1], values[2], values[3]}, nil
+	}
+	return Bezel{}, fmt.Errorf("invalid bezel %q: expected 1, 2 or 4 values", bezel)
+}
+
+// Span lays out a single picture across several monitors as if it were seen
+// through their frames, so that lines stay straight from one screen to the
+// next instead of jumping over the width of the bezels.
+type Span struct {
+	// Window is the bounding box of every monitor, in root coordinates.
+	Window Rect
+	// Canvas is the size of the physical surface formed by the monitors and
+	// the gaps between them. Media is fitted into it.
+	Canvas Rect
+	// Regions holds one entry per monitor, in the order they were given.
+	Regions []SpanRegion
+}
+
+// SpanRegion maps one monitor onto the canvas.
+type SpanRegion struct {
+	// Monitor is where the monitor is, relative to the span window.
+	Monitor Rect
+	// Canvas is the part of the canvas the monitor shows. It always has the
+	// size of Monitor.
+	Canvas Rect
+}
+
+// NewSpan builds the span for the given monitors. bezels[i] belongs to
+// monitors[i]; missing entries reuse the last bezel, or no bezel at all if
+// none were given.
+//
+// Gaps are inserted at every distinct left or top monitor edge, sized after
+// the widest bezels meeting there, so rows and columns of monitors stay
+// aligned on the canvas.
+func NewSpan(monitors []Rect, bezels []Bezel) Span {
+	var span Span
+	for _, monitor := range monitors {
+		span.Window = span.Window.Union(monitor)
+	}
+
+	bezel := func(i int) Bezel {
+		if len(bezels) == 0 {
+			return Bezel{}
+		}
+		return bezels[min(i, len(bezels)-1)]
+	}
+
+	xGaps := map[int]uint{}
+	yGaps := map[int]uint{}
+	for i, monitor := range monitors {
+		b := bezel(i)
+		xGaps[monitor.X] = max(xGaps[monitor.X], b.Left)
+		yGaps[monitor.Y] = max(yGaps[monitor.Y], b.Top)
+	}
+	xEnds := map[int]uint{}
+	yEnds := map[int]uint{}
+	for i, monitor := range monitors {
+		b := bezel(i)
+		xEnds[monitor.Right()] = max(xEnds[monitor.Right()], b.Right)
+		yEnds[monitor.Bottom()] = max(yEnds[monitor.Bottom()], b.Bottom)
+	}
+
+	shift := func(gaps, ends map[int]uint, at int) int {
+		edges := make([]int, 0, len(gaps))
+		for edge := range gaps {
+			edges = append(edges, edge)
+		}
+		slices.Sort(edges)
+		total := 0
+		for _, edge := range edges {
+			if edge > at {
+				break
+			}
+			total += int(gaps[edge] + ends[edge])
+		}
+		return total
+	}
+
+	var canvas Rect
+	span.Regions = make([]SpanRegion, len(monitors))
+	for i, monitor := range monitors {
+		physical := monitor.Translate(shift(xGaps, xEnds, monitor.X), shift(yGaps, yEnds, monitor.Y))
+		span.Regions[i] = SpanRegion{
+			Monitor: monitor.Translate(-span.Window.X, -span.Window.Y),
+			Canvas:  physical,
+		}
+		b := bezel(i)
+		canvas = canvas.Union(Rect{
+			X:      physical.X - int(b.Left),
+			Y:      physical.Y - int(b.Top),
+			Width:  physical.Width + b.Left + b.Right,
+			Height: physical.Height + b.Top + b.Bottom,
+		})
+	}
+
+	for i := range span.Regions {
+		span.Regions[i].Canvas = span.Regions[i].Canvas.Translate(-canvas.X, -canvas.Y)
+	}
+	span.Canvas = Rect{Width: canvas.Width, Height: canvas.Height}
+	return span
+}
@@ -0,0 +1,78 @@
+package geometry
+
+import "testing"
+
+func TestNewSpan(t *testing.T) {
+	monitors := []Rect{
+		{X: 0, Y: 0, Width: 1920, Height: 1080},
+		{X: 1920, Y: 0, Width: 1920, Height: 1080},
+	}
+	span := NewSpan(monitors, []Bezel{{Left: 50, Right: 50, Top: 20, Bottom: 20}})
+
+	if want := (Rect{Width: 3840, Height: 1080}); span.Window != want {
+		t.Fatalf("window: got %v, want %v", span.Window, want)
+	}
+	if want := (Rect{Width: 4040, Height: 1120}); span.Canvas != want {
+		t.Fatalf("canvas: got %v, want %v", span.Canvas, want)
+	}
+
+	want := []SpanRegion{
+		{Monitor: monitors[0], Canvas: Rect{X: 50, Y: 20, Width: 1920, Height: 1080}},
+		{Monitor: monitors[1], Canvas: Rect{X: 2070, Y: 20, Width: 1920, Height: 1080}},
+	}
+	for i, region := range span.Regions {
+		if region != want[i] {
+			t.Fatalf("region %d: got %+v, want %+v", i, region, want[i])
+		}
+	}
+}
+
+func TestNewSpanGrid(t *testing.T) {
+	monitors := []Rect{
+		{X: 100, Y: 100, Width: 100, Height: 100},
+		{X: 200, Y: 100, Width: 100, Height: 100},
+		{X: 100, Y: 200, Width: 100, Height: 100},
+		{X: 200, Y: 200, Width: 100, Height: 100},
+	}
+	span := NewSpan(monitors, []Bezel{{5, 5, 5, 5}, {0, 0, 0, 0}, {10, 10, 10, 10}, {0, 0, 0, 0}})
+
+	want := []Rect{
+		{X: 10, Y: 5, Width: 100, Height: 100},
+		{X: 120, Y: 5, Width: 100, Height: 100},
+		{X: 10, Y: 120, Width: 100, Height: 100},
+		{X: 120, Y: 120, Width: 100, Height: 100},
+	}
+	for i, region := range span.Regions {
+		if region.Canvas != want[i] {
+			t.Fatalf("region %d: got %v, want %v", i, region.Canvas, want[i])
+		}
+		if region.Monitor != monitors[i].Translate(-100, -100) {
+			t.Fatalf("region %d: monitor %v is not relative to the window", i, region.Monitor)
+		}
+	}
+	if want := (Rect{Width: 220, Height: 230}); span.Canvas != want {
+		t.Fatalf("canvas: got %v, want %v", span.Canvas, want)
+	}
+}
+
+func TestParseBezel(t *testing.T) {
+	tests := map[string]Bezel{
+		"40":      {40, 40, 40, 40},
+		"40:20":   {40, 40, 20, 20},
+		"1:2:3:4": {1, 2, 3, 4},
+	}
+	for input, want := range tests {
+		got, err := ParseBezel(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Fatalf("%q: got %+v, want %+v", input, got, want)
+		}
+	}
+	for _, input := range []string{"", "1:2:3", "-1", "a"} {
+		if _, err := ParseBezel(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package geometry
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Unit tells how the value of a Length is interpreted.
+type Unit int
+
+const (
+	// Pixels are absolute device pixels.
+	Pixels Unit = iota
+	// Percent is a percentage of the matching dimension of the reference
+	// rectangle: its width for widths and x offsets, its height otherwise.
+	Percent
+)
+
+// Length is a single component of a geometry specification.
+type Length struct {
+	Value float64
+	Unit  Unit
+}
+
+// Px returns a Length of n pixels.
+func Px(n int) Length {
+	return Length{Value: float64(n), Unit: Pixels}
+}
+
+// Pct returns a Length of p percent of the reference dimension.
+func Pct(p float64) Length {
+	return Length{Value: p, Unit: Percent}
+}
+
+// Resolve converts l to pixels against a reference dimension.
+func (l Length) Resolve(reference uint) int {
+	if l.Unit == Percent {
+		return int(math.Round(l.Value * float64(reference) / 100))
+	}
+	return int(l.Value)
+}
+
+func (l Length) String() string {
+	if l.Unit == Percent {
+		return strconv.FormatFloat(math.Abs(l.Value), 'f', -1, 64) + "%"
+	}
+	return strconv.FormatFloat(math.Abs(l.Value), 'f', 0, 64)
+}
+
+// Spec is a geometry specification whose components may be relative to the
+// screen it is placed on, such as "50%x100%+50%+0" or "100%x100%-0+30".
+type Spec struct {
+	Width, Height, X, Y Length
+	Flags               Flags
+}
+
+// ParseSpec parses a geometry specification using the same grammar as
+// ParseGeometry, except that every number may also be written as a
+// percentage, optionally with a fractional part.
+func ParseSpec(geometry string) (Spec, error) {
+	return parse(geometry, true)
+}
+
+// Resolve turns s into an absolute rectangle on the given screen. Missing
+// sizes default to the whole screen, missing offsets to zero, and negative
+// offsets are measured from the right or bottom edge.
+func (s Spec) Resolve(screen Rect) Rect {
+	return s.ResolveScaled(screen, 1)
+}
+
+// ResolveScaled is like Resolve, but treats pixel lengths in s as logical
+// pixels and converts them to device pixels using scale. The screen is in
+// device pixels.
+func (s Spec) ResolveScaled(screen Rect, scale Scale) Rect {
+	resolve := func(l Length, reference uint) int {
+		if l.Unit == Pixels {
+			return scale.Device(int(l.Value))
+		}
+		return l.Resolve(reference)
+	}
+
+	r := Rect{Width: screen.Width, Height: screen.Height}
+	if s.Flags&WidthValue != 0 {
+		r.Width = uint(max(resolve(s.Width, screen.Width), 0))
+	}
+	if s.Flags&HeightValue != 0 {
+		r.Height = uint(max(resolve(s.Height, screen.Height), 0))
+	}
+	if s.Flags&XValue != 0 {
+		r.X = resolve(s.X, screen.Width)
+	}
+	if s.Flags&YValue != 0 {
+		r.Y = resolve(s.Y, screen.Height)
+	}
+	if s.Flags&XNegative != 0 {
+		r.X += int(screen.Width) - int(r.Width)
+	}
+	if s.Flags&YNegative != 0 {
+		r.Y += int(screen.Height) - int(r.Height)
+	}
+	return r.Translate(screen.X, screen.Y)
+}
+
+// String formats s back into a geometry specification.
+func (s Spec) String() string {
+	var b strings.Builder
+	if s.Flags&WidthValue != 0 {
+		b.WriteString(s.Width.String())
+	}
+	if s.Flags&HeightValue != 0 {
+		b.WriteByte('x')
+		b.WriteString(s.Height.String())
+	}
+	if s.Flags&XValue != 0 {
+		b.WriteString(sign(s.Flags&XNegative != 0))
+		b.WriteString(s.X.String())
+	}
+	if s.Flags&YValue != 0 {
+		b.WriteString(sign(s.Flags&YNegative != 0))
+		b.WriteString(s.Y.String())
+	}
+	return b.String()
+}
+
+func sign(negative bool) string {
+	if negative {
+		return "-"
+	}
+	return "+"
+}
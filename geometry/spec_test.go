@@ -0,0 +1,54 @@
+package geometry
+
+import "testing"
+
+func TestSpecResolve(t *testing.T) {
+	laptop := Rect{Width: 1366, Height: 768}
+	desk := Rect{X: 1366, Width: 3840, Height: 2160}
+
+	tests := []struct {
+		input  string
+		screen Rect
+		want   Rect
+	}{
+		{"50%x100%+50%+0", laptop, Rect{X: 683, Y: 0, Width: 683, Height: 768}},
+		{"50%x100%+50%+0", desk, Rect{X: 3286, Y: 0, Width: 1920, Height: 2160}},
+		{"100%x100%-0+30", desk, Rect{X: 1366, Y: 30, Width: 3840, Height: 2160}},
+		{"33.5%x10%-25%-0", Rect{Width: 1000, Height: 1000}, Rect{X: 415, Y: 900, Width: 335, Height: 100}},
+		{"800x50%", laptop, Rect{Width: 800, Height: 384}},
+		{"", desk, desk},
+	}
+
+	for _, test := range tests {
+		spec, err := ParseSpec(test.input)
+		if err != nil {
+			t.Fatalf("%q: %v", test.input, err)
+		}
+		if got := spec.Resolve(test.screen); got != test.want {
+			t.Fatalf("%q on %v: got %v, want %v", test.input, test.screen, got, test.want)
+		}
+	}
+}
+
+func TestSpecString(t *testing.T) {
+	for _, input := range []string{"50%x100%+50%+0", "100%x100%-0+30", "33.5%x480-12.25%-0", "640", "x10%", "+0-0", ""} {
+		spec, err := ParseSpec(input)
+		if err != nil {
+			t.Fatalf("%q: %v", input, err)
+		}
+		if got := spec.String(); got != input {
+			t.Fatalf("got %q, want %q", got, input)
+		}
+	}
+}
+
+func TestParseSpecInvalid(t *testing.T) {
+	for _, input := range []string{"%x10", "10.5x10", "50%%", "1.%x.5%", "50%x"} {
+		if _, err := ParseSpec(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+	if _, _, _, _, _, err := ParseGeometry("50%x100%"); err == nil {
+		t.Fatal("ParseGeometry should reject percentages")
+	}
+}
@@ -0,0 +1,148 @@
+package geometry
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Transform is one of the eight ways content can be rotated by quarter turns
+// and mirrored. The content is first flipped horizontally if Flip is set, then
+// rotated clockwise by Rotation degrees, one of 0, 90, 180 or 270.
+type Transform struct {
+	Rotation int
+	Flip     bool
+}
+
+// Identity leaves content unchanged.
+var Identity = Transform{}
+
+// Rotate returns the transform that rotates content clockwise by degrees,
+// which are rounded down to a quarter turn.
+func Rotate(degrees int) Transform {
+	return Transform{Rotation: ((degrees/90)%4 + 4) % 4 * 90}
+}
+
+// FlipX returns the transform that mirrors content horizontally.
+func FlipX() Transform {
+	return Transform{Flip: true}
+}
+
+// FlipY returns the transform that mirrors content vertically.
+func FlipY() Transform {
+	return Transform{Rotation: 180, Flip: true}
+}
+
+// ParseTransform parses a comma separated list of steps, each either a
+// clockwise rotation in degrees or one of "flip-x" and "flip-y", applied in
+// order. "none" and the empty string are the identity.
+func ParseTransform(s string) (Transform, error) {
+	t := Identity
+	if s == "" || s == "none" {
+		return t, nil
+	}
+	for _, step := range strings.Split(s, ",") {
+		switch step {
+		case "flip-x":
+			t = t.Then(FlipX())
+		case "flip-y":
+			t = t.Then(FlipY())
+		default:
+			degrees, err := strconv.Atoi(step)
+			if err != nil || degrees%90 != 0 {
+				return Identity, fmt.Errorf("invalid transform step %q", step)
+			}
+			t = t.Then(Rotate(degrees))
+		}
+	}
+	return t, nil
+}
+
+// matrix is a transform as a 2x2 matrix acting on column vectors in X11
+// coordinates, where y grows downwards.
+type matrix [4]int
+
+func (t Transform) matrix() matrix {
+	m := matrix{1, 0, 0, 1}
+	if t.Flip {
+		m = matrix{-1, 0, 0, 1}
+	}
+	for i := 0; i < t.Rotation/90; i++ {
+		// A clockwise quarter turn maps (x, y) to (-y, x).
+		m = matrix{-m[2], -m[3], m[0], m[1]}
+	}
+	return m
+}
+
+func fromMatrix(m matrix) Transform {
+	var t Transform
+	if m[0]*m[3]-m[1]*m[2] < 0 {
+		t.Flip = true
+		// Undo the flip, which is its own inverse, leaving a pure rotation.
+		m = matrix{-m[0], m[1], -m[2], m[3]}
+	}
+	switch {
+	case m[0] == 1:
+		t.Rotation = 0
+	case m[2] == 1:
+		t.Rotation = 90
+	case m[0] == -1:
+		t.Rotation = 180
+	default:
+		t.Rotation = 270
+	}
+	return t
+}
+
+// Then returns the transform that applies t and then u.
+func (t Transform) Then(u Transform) Transform {
+	a, b := u.matrix(), t.matrix()
+	return fromMatrix(matrix{
+		a[0]*b[0] + a[1]*b[2], a[0]*b[1] + a[1]*b[3],
+		a[2]*b[0] + a[3]*b[2], a[2]*b[1] + a[3]*b[3],
+	})
+}
+
+// Inverse returns the transform that undoes t.
+func (t Transform) Inverse() Transform {
+	if t.Flip {
+		// Flipping and rotating are both undone by applying them again in
+		// reverse order, and a flip turns a clockwise rotation into a
+		// counterclockwise one, so the two cancel out.
+		return t
+	}
+	return Rotate(360 - t.Rotation)
+}
+
+// SwapsAxes reports whether t turns landscape content into portrait.
+func (t Transform) SwapsAxes() bool {
+	return t.Rotation == 90 || t.Rotation == 270
+}
+
+// Size returns the size of r once transformed by t.
+func (t Transform) Size(r Rect) Rect {
+	if t.SwapsAxes() {
+		return Rect{Width: r.Height, Height: r.Width}
+	}
+	return Rect{Width: r.Width, Height: r.Height}
+}
+
+// SourcePoint returns where the point x, y of content transformed by t was
+// before the transform. Both points are given in fractions of the width and
+// height of their content, from its top left corner.
+func (t Transform) SourcePoint(x, y float64) (float64, float64) {
+	m := t.Inverse().matrix()
+	x, y = x-0.5, y-0.5
+	return float64(m[0])*x + float64(m[1])*y + 0.5, float64(m[2])*x + float64(m[3])*y + 0.5
+}
+
+func (t Transform) String() string {
+	var steps []string
+	if t.Flip {
+		steps = append(steps, "flip-x")
+	}
+	if t.Rotation != 0 || !t.Flip {
+		steps = append(steps, strconv.Itoa(t.Rotation))
+	}
+	return strings.Join(steps, ",")
+}
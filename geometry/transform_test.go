@@ -0,0 +1,86 @@
+package geometry
+
+import "testing"
+
+var allTransforms = []Transform{
+	{0, false}, {90, false}, {180, false}, {270, false},
+	{0, true}, {90, true}, {180, true}, {270, true},
+}
+
+func TestTransformThen(t *testing.T) {
+	if got := Rotate(90).Then(Rotate(180)); got != Rotate(270) {
+		t.Fatalf("90+180: got %v", got)
+	}
+	if got := FlipX().Then(FlipY()); got != Rotate(180) {
+		t.Fatalf("flipping both axes should be a half turn, got %v", got)
+	}
+	if got := Rotate(90).Then(FlipX()); got != (Transform{Rotation: 270, Flip: true}) {
+		t.Fatalf("90 then flip-x: got %v", got)
+	}
+	if got := FlipX().Then(Rotate(90)); got != (Transform{Rotation: 90, Flip: true}) {
+		t.Fatalf("flip-x then 90: got %v", got)
+	}
+}
+
+func TestTransformInverse(t *testing.T) {
+	for _, transform := range allTransforms {
+		if got := transform.Then(transform.Inverse()); got != Identity {
+			t.Fatalf("%v then its inverse %v gave %v", transform, transform.Inverse(), got)
+		}
+		if got := transform.Inverse().Then(transform); got != Identity {
+			t.Fatalf("inverse of %v then itself gave %v", transform, got)
+		}
+	}
+}
+
+func TestTransformSize(t *testing.T) {
+	video := Rect{Width: 1920, Height: 1080}
+	if got := Rotate(270).Size(video); got != (Rect{Width: 1080, Height: 1920}) {
+		t.Fatalf("got %v", got)
+	}
+	if got := FlipY().Size(video); got != video {
+		t.Fatalf("got %v", got)
+	}
+}
+
+func TestTransformSourcePoint(t *testing.T) {
+	tests := []struct {
+		transform Transform
+		x, y      float64
+		sx, sy    float64
+	}{
+		{Identity, 0.25, 0, 0.25, 0},
+		{Rotate(90), 1, 0, 0, 0},
+		{Rotate(90), 1, 1, 1, 0},
+		{Rotate(180), 0, 0, 1, 1},
+		{Rotate(270), 0, 0, 1, 0},
+		{FlipX(), 0, 0.25, 1, 0.25},
+		{FlipY(), 0.25, 0, 0.25, 1},
+		{Transform{Rotation: 90, Flip: true}, 0, 0, 1, 1},
+	}
+	for _, test := range tests {
+		if sx, sy := test.transform.SourcePoint(test.x, test.y); sx != test.sx || sy != test.sy {
+			t.Fatalf("%v: %v,%v came from %v,%v, want %v,%v", test.transform, test.x, test.y, sx, sy, test.sx, test.sy)
+		}
+	}
+}
+
+func TestParseTransform(t *testing.T) {
+	for _, transform := range allTransforms {
+		parsed, err := ParseTransform(transform.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed != transform {
+			t.Fatalf("%q: got %v", transform.String(), parsed)
+		}
+	}
+	if got, err := ParseTransform("-90,flip-y"); err != nil || got != (Transform{Rotation: 270, Flip: true}) {
+		t.Fatalf("got %v, %v", got, err)
+	}
+	for _, input := range []string{"45", "flip", "90,"} {
+		if _, err := ParseTransform(input); err == nil {
+			t.Fatalf("%q: expected an error", input)
+		}
+	}
+}
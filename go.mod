@@ -2,6 +2,8 @@ module github.com/zSnails/peruere
 
 go 1.22.5
 
+// libmpv reads the mpv handle out of go-mpv's Mpv, whose layout
+// TestMpvLayout checks; run it before upgrading.
 require github.com/gen2brain/go-mpv v0.2.3
 
 require (
@@ -0,0 +1,17 @@
+package libmpv
+
+// #include <mpv/client.h>
+import "C"
+import (
+	"unsafe"
+
+	"github.com/gen2brain/go-mpv"
+)
+
+// mpvHandle returns the mpv_handle of m. go-mpv has no accessor for it, so
+// it is read from the unexported handle field, which is all there is to Mpv
+// as of v0.2.3 both with and without cgo. TestMpvLayout fails if that
+// changes; check it before upgrading go-mpv.
+func mpvHandle(m *mpv.Mpv) *C.mpv_handle {
+	return *(**C.mpv_handle)(unsafe.Pointer(m))
+}
@@ -0,0 +1,28 @@
+package libmpv
+
+import (
+	"reflect"
+	"testing"
+	"unsafe"
+
+	"github.com/gen2brain/go-mpv"
+)
+
+// TestMpvLayout checks that mpv.Mpv is still nothing but the handle that
+// mpvHandle reads.
+func TestMpvLayout(t *testing.T) {
+	typ := reflect.TypeOf(mpv.Mpv{})
+	if typ.NumField() != 1 {
+		t.Fatalf("mpv.Mpv has %d fields, want only the handle", typ.NumField())
+	}
+	field := typ.Field(0)
+	if field.Name != "handle" || field.Offset != 0 {
+		t.Errorf("the first field of mpv.Mpv is %s at offset %d, want handle at 0", field.Name, field.Offset)
+	}
+	if kind := field.Type.Kind(); kind != reflect.Pointer && kind != reflect.Uintptr {
+		t.Errorf("the handle of mpv.Mpv is a %v, want a pointer", kind)
+	}
+	if typ.Size() != unsafe.Sizeof(uintptr(0)) {
+		t.Errorf("mpv.Mpv takes %d bytes, want the size of a pointer", typ.Size())
+	}
+}
@@ -0,0 +1,326 @@
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+#include <EGL/egl.h>
+#include <EGL/eglext.h>
+#include <GLES3/gl3.h>
+#include <mpv/render_gl.h>
+#include "render.h"
+
+extern void goRenderUpdate ( uintptr_t handle );
+
+struct render {
+	Display *x;
+	EGLDisplay display;
+	EGLConfig config;
+	EGLContext context;
+	EGLSurface pbuffer;
+	int es3;
+	GLuint program, texture, framebuffer;
+	GLint position, coordinate;
+	int width, height;
+	mpv_render_context *mpv;
+};
+
+static const char *const vertex_shader =
+	"attribute vec2 position;\n"
+	"attribute vec2 coordinate;\n"
+	"varying vec2 v_coordinate;\n"
+	"void main() {\n"
+	"	v_coordinate = coordinate;\n"
+	"	gl_Position = vec4(position, 0.0, 1.0);\n"
+	"}\n";
+
+static const char *const fragment_shader =
+	"precision mediump float;\n"
+	"uniform sampler2D frame;\n"
+	"varying vec2 v_coordinate;\n"
+	"void main() {\n"
+	"	gl_FragColor = texture2D(frame, v_coordinate);\n"
+	"}\n";
+
+static void on_update ( void *const ctx ) {
+	goRenderUpdate((uintptr_t)ctx);
+}
+
+static void *get_proc_address ( void *const ctx, const char *const name ) {
+	(void)ctx;
+	return (void *)eglGetProcAddress(name);
+}
+
+static EGLDisplay get_display ( Display *const x ) {
+	const char *const extensions = eglQueryString(EGL_NO_DISPLAY, EGL_EXTENSIONS);
+	if (extensions != NULL && strstr(extensions, "EGL_EXT_platform_x11") != NULL) {
+		const PFNEGLGETPLATFORMDISPLAYEXTPROC get_platform_display =
+			(PFNEGLGETPLATFORMDISPLAYEXTPROC)eglGetProcAddress("eglGetPlatformDisplayEXT");
+		if (get_platform_display != NULL)
+			return get_platform_display(EGL_PLATFORM_X11_EXT, x, NULL);
+	}
+	return eglGetDisplay((EGLNativeDisplayType)x);
+}
+
+/* choose_config picks a config that window surfaces can be made with for
+   the wallpaper windows, which use the default visual. */
+static int choose_config ( render *const r ) {
+	static const EGLint attributes[] = {
+		EGL_SURFACE_TYPE, EGL_WINDOW_BIT | EGL_PBUFFER_BIT,
+		EGL_RENDERABLE_TYPE, EGL_OPENGL_ES2_BIT,
+		EGL_RED_SIZE, 8,
+		EGL_GREEN_SIZE, 8,
+		EGL_BLUE_SIZE, 8,
+		EGL_NONE,
+	};
+	EGLConfig configs[64];
+	EGLint count = 0;
+	if (!eglChooseConfig(r->display, attributes, configs, 64, &count) || count == 0)
+		return 0;
+	const VisualID visual = XVisualIDFromVisual(DefaultVisual(r->x, DefaultScreen(r->x)));
+	r->config = configs[0];
+	for (EGLint i = 0; i < count; i++) {
+		EGLint id;
+		if (eglGetConfigAttrib(r->display, configs[i], EGL_NATIVE_VISUAL_ID, &id) && (VisualID)id == visual) {
+			r->config = configs[i];
+			break;
+		}
+	}
+	return 1;
+}
+
+static GLuint compile_shader ( const GLenum type, const char *const source ) {
+	const GLuint shader = glCreateShader(type);
+	GLint compiled = GL_FALSE;
+	glShaderSource(shader, 1, &source, NULL);
+	glCompileShader(shader);
+	glGetShaderiv(shader, GL_COMPILE_STATUS, &compiled);
+	if (!compiled) {
+		glDeleteShader(shader);
+		return 0;
+	}
+	return shader;
+}
+
+static GLuint link_program ( void ) {
+	const GLuint vertex = compile_shader(GL_VERTEX_SHADER, vertex_shader);
+	const GLuint fragment = compile_shader(GL_FRAGMENT_SHADER, fragment_shader);
+	GLuint program = 0;
+	if (vertex != 0 && fragment != 0) {
+		GLint linked = GL_FALSE;
+		program = glCreateProgram();
+		glAttachShader(program, vertex);
+		glAttachShader(program, fragment);
+		glLinkProgram(program);
+		glGetProgramiv(program, GL_LINK_STATUS, &linked);
+		if (!linked) {
+			glDeleteProgram(program);
+			program = 0;
+		}
+	}
+	glDeleteShader(vertex);
+	glDeleteShader(fragment);
+	return program;
+}
+
+render *render_create ( mpv_handle *const mpv, const uintptr_t handle, char *const error, const size_t error_size ) {
+	static const EGLint context_es3[] = { EGL_CONTEXT_CLIENT_VERSION, 3, EGL_NONE };
+	static const EGLint context_es2[] = { EGL_CONTEXT_CLIENT_VERSION, 2, EGL_NONE };
+	static const EGLint pbuffer[] = { EGL_WIDTH, 1, EGL_HEIGHT, 1, EGL_NONE };
+	const char *message = NULL;
+	render *const r = calloc(1, sizeof(render));
+	if (r == NULL) {
+		snprintf(error, error_size, "out of memory");
+		return NULL;
+	}
+
+	/* The renderer has a connection of its own, since it draws from a
+	   thread of its own. */
+	r->x = XOpenDisplay(NULL);
+	if (r->x == NULL) {
+		message = "cannot open the X display";
+		goto fail;
+	}
+	r->display = get_display(r->x);
+	if (r->display == EGL_NO_DISPLAY || !eglInitialize(r->display, NULL, NULL)) {
+		message = "cannot initialize EGL";
+		goto fail;
+	}
+	if (!eglBindAPI(EGL_OPENGL_ES_API) || !choose_config(r)) {
+		message = "EGL has no config for OpenGL ES 2";
+		goto fail;
+	}
+	r->context = eglCreateContext(r->display, r->config, EGL_NO_CONTEXT, context_es3);
+	r->es3 = r->context != EGL_NO_CONTEXT;
+	if (!r->es3)
+		r->context = eglCreateContext(r->display, r->config, EGL_NO_CONTEXT, context_es2);
+	if (r->context == EGL_NO_CONTEXT) {
+		message = "cannot create an EGL context";
+		goto fail;
+	}
+	/* Frames are drawn into a texture before any window surface exists, so
+	   the context is made current with a surface that is never shown. */
+	r->pbuffer = eglCreatePbufferSurface(r->display, r->config, pbuffer);
+	if (r->pbuffer == EGL_NO_SURFACE || !eglMakeCurrent(r->display, r->pbuffer, r->pbuffer, r->context)) {
+		message = "cannot make the EGL context current";
+		goto fail;
+	}
+	r->program = link_program();
+	if (r->program == 0) {
+		message = "cannot build the shaders";
+		goto fail;
+	}
+	r->position = glGetAttribLocation(r->program, "position");
+	r->coordinate = glGetAttribLocation(r->program, "coordinate");
+	glGenTextures(1, &r->texture);
+	glGenFramebuffers(1, &r->framebuffer);
+
+	mpv_opengl_init_params gl = { .get_proc_address = get_proc_address };
+	mpv_render_param params[] = {
+		{ MPV_RENDER_PARAM_API_TYPE, (void *)MPV_RENDER_API_TYPE_OPENGL },
+		{ MPV_RENDER_PARAM_OPENGL_INIT_PARAMS, &gl },
+		/* Lets hardware decoders hand their frames over without a copy. */
+		{ MPV_RENDER_PARAM_X11_DISPLAY, r->x },
+		{ MPV_RENDER_PARAM_INVALID, NULL },
+	};
+	const int result = mpv_render_context_create(&r->mpv, mpv, params);
+	if (result < 0) {
+		r->mpv = NULL;
+		snprintf(error, error_size, "cannot create the mpv render context: %s", mpv_error_string(result));
+		render_free(r);
+		return NULL;
+	}
+	mpv_render_context_set_update_callback(r->mpv, on_update, (void *)handle);
+	return r;
+
+fail:
+	snprintf(error, error_size, "%s", message);
+	render_free(r);
+	return NULL;
+}
+
+void *render_surface ( render *const r, const Window window ) {
+	const EGLSurface surface = eglCreateWindowSurface(r->display, r->config, (EGLNativeWindowType)window, NULL);
+	return surface == EGL_NO_SURFACE ? NULL : surface;
+}
+
+void render_destroy_surface ( render *const r, void *const surface ) {
+	eglMakeCurrent(r->display, r->pbuffer, r->pbuffer, r->context);
+	eglDestroySurface(r->display, surface);
+}
+
+int render_update ( render *const r ) {
+	return (mpv_render_context_update(r->mpv) & MPV_RENDER_UPDATE_FRAME) != 0;
+}
+
+const char *render_frame ( render *const r, const int width, const int height, const int block ) {
+	if (!eglMakeCurrent(r->display, r->pbuffer, r->pbuffer, r->context))
+		return "cannot make the EGL context current";
+	if (width != r->width || height != r->height) {
+		glBindTexture(GL_TEXTURE_2D, r->texture);
+		glTexImage2D(GL_TEXTURE_2D, 0, GL_RGBA, width, height, 0, GL_RGBA, GL_UNSIGNED_BYTE, NULL);
+		glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_MIN_FILTER, GL_LINEAR);
+		glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_MAG_FILTER, GL_LINEAR);
+		glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_WRAP_S, GL_CLAMP_TO_EDGE);
+		glTexParameteri(GL_TEXTURE_2D, GL_TEXTURE_WRAP_T, GL_CLAMP_TO_EDGE);
+		glBindFramebuffer(GL_FRAMEBUFFER, r->framebuffer);
+		glFramebufferTexture2D(GL_FRAMEBUFFER, GL_COLOR_ATTACHMENT0, GL_TEXTURE_2D, r->texture, 0);
+		if (glCheckFramebufferStatus(GL_FRAMEBUFFER) != GL_FRAMEBUFFER_COMPLETE) {
+			r->width = r->height = 0;
+			return "cannot draw into a texture of the size of the video";
+		}
+		r->width = width;
+		r->height = height;
+	}
+
+	/* Without flipping, the top row of the video is the first row of the
+	   texture, so texture coordinates run down the video like X11 ones. */
+	mpv_opengl_fbo fbo = { .fbo = (int)r->framebuffer, .w = width, .h = height };
+	int flip_y = 0;
+	int block_for_target_time = block;
+	mpv_render_param params[] = {
+		{ MPV_RENDER_PARAM_OPENGL_FBO, &fbo },
+		{ MPV_RENDER_PARAM_FLIP_Y, &flip_y },
+		{ MPV_RENDER_PARAM_BLOCK_FOR_TARGET_TIME, &block_for_target_time },
+		{ MPV_RENDER_PARAM_INVALID, NULL },
+	};
+	const int result = mpv_render_context_render(r->mpv, params);
+	return result < 0 ? mpv_error_string(result) : NULL;
+}
+
+const char *render_view ( render *const r, void *const surface, const int swap_interval,
+                          const int width, const int height,
+                          const int x, const int y, const int view_width, const int view_height,
+                          const float *const corners, unsigned char *const pixels ) {
+	/* A triangle strip through the top left, bottom left, top right and
+	   bottom right corners, in OpenGL coordinates where y points up. */
+	static const GLfloat positions[] = { -1, 1, -1, -1, 1, 1, 1, -1 };
+	const GLfloat coordinates[] = {
+		corners[0], corners[1],
+		corners[4], corners[5],
+		corners[2], corners[3],
+		corners[6], corners[7],
+	};
+
+	if (!eglMakeCurrent(r->display, surface, surface, r->context))
+		return "cannot make a window surface current";
+	eglSwapInterval(r->display, swap_interval);
+
+	/* mpv leaves its own state behind. */
+	if (r->es3) {
+		glBindVertexArray(0);
+		glBindBuffer(GL_PIXEL_PACK_BUFFER, 0);
+	}
+	glBindBuffer(GL_ARRAY_BUFFER, 0);
+	glBindFramebuffer(GL_FRAMEBUFFER, 0);
+	glDisable(GL_BLEND);
+	glDisable(GL_SCISSOR_TEST);
+
+	glViewport(0, 0, width, height);
+	glClearColor(0, 0, 0, 1);
+	glClear(GL_COLOR_BUFFER_BIT);
+	if (r->width > 0 && view_width > 0 && view_height > 0) {
+		glViewport(x, height - y - view_height, view_width, view_height);
+		glUseProgram(r->program);
+		glActiveTexture(GL_TEXTURE0);
+		glBindTexture(GL_TEXTURE_2D, r->texture);
+		glVertexAttribPointer(r->position, 2, GL_FLOAT, GL_FALSE, 0, positions);
+		glVertexAttribPointer(r->coordinate, 2, GL_FLOAT, GL_FALSE, 0, coordinates);
+		glEnableVertexAttribArray(r->position);
+		glEnableVertexAttribArray(r->coordinate);
+		glDrawArrays(GL_TRIANGLE_STRIP, 0, 4);
+		glDisableVertexAttribArray(r->position);
+		glDisableVertexAttribArray(r->coordinate);
+	}
+	if (pixels != NULL) {
+		glPixelStorei(GL_PACK_ALIGNMENT, 1);
+		glReadPixels(0, 0, width, height, GL_RGBA, GL_UNSIGNED_BYTE, pixels);
+	}
+	if (!eglSwapBuffers(r->display, surface))
+		return "cannot swap the buffers of a window surface";
+	return NULL;
+}
+
+void render_report_swap ( render *const r ) {
+	mpv_render_context_report_swap(r->mpv);
+}
+
+void render_free ( render *const r ) {
+	const int current = r->context != EGL_NO_CONTEXT && r->pbuffer != EGL_NO_SURFACE &&
+		eglMakeCurrent(r->display, r->pbuffer, r->pbuffer, r->context);
+	/* mpv frees GL objects of its own, so the context has to be current. */
+	if (r->mpv != NULL)
+		mpv_render_context_free(r->mpv);
+	if (current) {
+		glDeleteFramebuffers(1, &r->framebuffer);
+		glDeleteTextures(1, &r->texture);
+		glDeleteProgram(r->program);
+		eglMakeCurrent(r->display, EGL_NO_SURFACE, EGL_NO_SURFACE, EGL_NO_CONTEXT);
+	}
+	if (r->pbuffer != EGL_NO_SURFACE)
+		eglDestroySurface(r->display, r->pbuffer);
+	if (r->context != EGL_NO_CONTEXT)
+		eglDestroyContext(r->display, r->context);
+	if (r->display != EGL_NO_DISPLAY)
+		eglTerminate(r->display);
+	if (r->x != NULL)
+		XCloseDisplay(r->x);
+	free(r);
+}
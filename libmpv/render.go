@@ -0,0 +1,239 @@
+package libmpv
+
+// #cgo LDFLAGS: -lmpv -lEGL -lGLESv2 -lX11
+// #include <stdlib.h>
+// #include "render.h"
+import "C"
+import (
+	"errors"
+	"image"
+	"log"
+	"runtime"
+	"runtime/cgo"
+	"unsafe"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// Renderer draws the video of an mpv instance into several windows through
+// the render API. mpv decodes and draws each frame once, into a texture on
+// the GPU, which is then drawn into every window. The OpenGL context belongs
+// to a goroutine of the Renderer, locked to its thread.
+type Renderer struct {
+	handle    cgo.Handle
+	update    chan struct{}
+	views     chan viewSet
+	snapshots chan chan snapshot
+	quit      chan struct{}
+	done      chan struct{}
+}
+
+type viewSet struct {
+	source geometry.Rect
+	views  []View
+}
+
+type snapshot struct {
+	images []image.Image
+	err    error
+}
+
+// NewRenderer attaches a Renderer to m, which must have been initialized
+// with the vo option set to libmpv and must not have loaded anything yet.
+// Close the Renderer before terminating m.
+func NewRenderer(m *mpv.Mpv) (*Renderer, error) {
+	r := &Renderer{
+		update:    make(chan struct{}, 1),
+		views:     make(chan viewSet),
+		snapshots: make(chan chan snapshot),
+		quit:      make(chan struct{}),
+		done:      make(chan struct{}),
+	}
+	r.handle = cgo.NewHandle(r)
+	ready := make(chan error)
+	go r.run(mpvHandle(m), ready)
+	if err := <-ready; err != nil {
+		<-r.done
+		r.handle.Delete()
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetViews makes the Renderer draw video of the source size, as mpv reports
+// it, into the given views. Windows that are no longer in a view are left
+// alone from then on.
+func (r *Renderer) SetViews(source geometry.Rect, views []View) {
+	select {
+	case r.views <- viewSet{source, views}:
+	case <-r.done:
+	}
+}
+
+// Snapshot draws the current frame again and returns what each view shows,
+// in the order of the views.
+func (r *Renderer) Snapshot() ([]image.Image, error) {
+	reply := make(chan snapshot)
+	select {
+	case r.snapshots <- reply:
+	case <-r.done:
+		return nil, errors.New("the renderer is closed")
+	}
+	s := <-reply
+	return s.images, s.err
+}
+
+// Close stops drawing and detaches the Renderer from its mpv instance.
+func (r *Renderer) Close() {
+	close(r.quit)
+	<-r.done
+	r.handle.Delete()
+}
+
+func (r *Renderer) run(m *C.mpv_handle, ready chan<- error) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+	defer close(r.done)
+
+	var message [256]C.char
+	state := C.render_create(m, C.uintptr_t(r.handle), &message[0], C.size_t(len(message)))
+	if state == nil {
+		ready <- errors.New(C.GoString(&message[0]))
+		return
+	}
+	ready <- nil
+	defer C.render_free(state)
+
+	surfaces := make(map[xlib.Window]unsafe.Pointer)
+	defer func() {
+		for _, surface := range surfaces {
+			C.render_destroy_surface(state, surface)
+		}
+	}()
+
+	var current viewSet
+	var lastErr string
+	report := func(err error) {
+		// A broken surface fails the same way on every frame.
+		if err != nil && err.Error() != lastErr {
+			log.Println("mirror:", err)
+		}
+		if err == nil {
+			lastErr = ""
+		} else {
+			lastErr = err.Error()
+		}
+	}
+	for {
+		select {
+		case <-r.quit:
+			return
+		case <-r.update:
+			if C.render_update(state) != 0 {
+				report(draw(state, surfaces, current, true, nil))
+			}
+		case set := <-r.views:
+			wanted := make(map[xlib.Window]bool)
+			for _, view := range set.views {
+				wanted[view.Window] = true
+				if surfaces[view.Window] != nil {
+					continue
+				}
+				surface := C.render_surface(state, C.Window(view.Window))
+				if surface == nil {
+					log.Printf("mirror: cannot make a surface for window %#x\n", view.Window)
+					continue
+				}
+				surfaces[view.Window] = surface
+			}
+			for window, surface := range surfaces {
+				if !wanted[window] {
+					C.render_destroy_surface(state, surface)
+					delete(surfaces, window)
+				}
+			}
+			current = set
+			report(draw(state, surfaces, current, false, nil))
+		case reply := <-r.snapshots:
+			pixels := make([][]byte, len(current.views))
+			for i, view := range current.views {
+				pixels[i] = make([]byte, view.Width*view.Height*4)
+			}
+			if err := draw(state, surfaces, current, false, pixels); err != nil {
+				reply <- snapshot{err: err}
+				continue
+			}
+			images := make([]image.Image, len(current.views))
+			for i, view := range current.views {
+				images[i] = flipRows(pixels[i], int(view.Width), int(view.Height))
+			}
+			reply <- snapshot{images: images}
+		}
+	}
+}
+
+// draw has mpv draw the current frame into its texture and draws that into
+// every view, reading the windows back into pixels if it is not nil. Only
+// the first window waits for vertical blank, so that the others do not hold
+// up the frame.
+func draw(state *C.render, surfaces map[xlib.Window]unsafe.Pointer, set viewSet, block bool, pixels [][]byte) error {
+	if set.source.Empty() {
+		return errors.New("the size of the video is not known yet")
+	}
+	blockC := C.int(0)
+	if block {
+		blockC = 1
+	}
+	if message := C.render_frame(state, C.int(set.source.Width), C.int(set.source.Height), blockC); message != nil {
+		return errors.New(C.GoString(message))
+	}
+	for i, view := range set.views {
+		surface := surfaces[view.Window]
+		if surface == nil {
+			continue
+		}
+		var corners [8]C.float
+		for j, corner := range view.Corners {
+			corners[2*j], corners[2*j+1] = C.float(corner[0]), C.float(corner[1])
+		}
+		interval := C.int(0)
+		if i == 0 {
+			interval = 1
+		}
+		var pixelsC *C.uchar
+		if pixels != nil && len(pixels[i]) > 0 {
+			pixelsC = (*C.uchar)(unsafe.Pointer(&pixels[i][0]))
+		}
+		message := C.render_view(state, surface, interval,
+			C.int(view.Width), C.int(view.Height),
+			C.int(view.Visible.X), C.int(view.Visible.Y), C.int(view.Visible.Width), C.int(view.Visible.Height),
+			&corners[0], pixelsC)
+		if message != nil {
+			return errors.New(C.GoString(message))
+		}
+	}
+	C.render_report_swap(state)
+	return nil
+}
+
+// flipRows turns pixels read back from OpenGL, bottom row first, into an
+// image.
+func flipRows(pixels []byte, width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	stride := width * 4
+	for y := 0; y < height; y++ {
+		copy(img.Pix[y*img.Stride:y*img.Stride+stride], pixels[(height-1-y)*stride:(height-y)*stride])
+	}
+	return img
+}
+
+//export goRenderUpdate
+func goRenderUpdate(handle C.uintptr_t) {
+	r := cgo.Handle(handle).Value().(*Renderer)
+	select {
+	case r.update <- struct{}{}:
+	default:
+	}
+}
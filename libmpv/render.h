@@ -0,0 +1,23 @@
+#ifndef LIBMPV_RENDER_H
+#define LIBMPV_RENDER_H
+
+#include <stddef.h>
+#include <stdint.h>
+#include <X11/Xlib.h>
+#include <mpv/client.h>
+
+typedef struct render render;
+
+extern render *render_create ( mpv_handle *const mpv, const uintptr_t handle, char *const error, const size_t error_size );
+extern void *render_surface ( render *const r, const Window window );
+extern void render_destroy_surface ( render *const r, void *const surface );
+extern int render_update ( render *const r );
+extern const char *render_frame ( render *const r, const int width, const int height, const int block );
+extern const char *render_view ( render *const r, void *const surface, const int swap_interval,
+                                 const int width, const int height,
+                                 const int x, const int y, const int view_width, const int view_height,
+                                 const float *const corners, unsigned char *const pixels );
+extern void render_report_swap ( render *const r );
+extern void render_free ( render *const r );
+
+#endif /* LIBMPV_RENDER_H */
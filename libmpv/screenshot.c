@@ -0,0 +1,47 @@
+#include <string.h>
+#include "screenshot.h"
+
+static const mpv_node *find ( const mpv_node *const map, const char *const key, const mpv_format format ) {
+	if (map->format != MPV_FORMAT_NODE_MAP)
+		return NULL;
+	const mpv_node_list *const list = map->u.list;
+	for (int i = 0; i < list->num; i++) {
+		if (strcmp(list->keys[i], key) == 0)
+			return list->values[i].format == format ? &list->values[i] : NULL;
+	}
+	return NULL;
+}
+
+const char *screenshot_raw ( mpv_handle *const mpv, const char *const flags, screenshot *const s ) {
+	mpv_node args[2] = {
+		{ .u.string = "screenshot-raw", .format = MPV_FORMAT_STRING },
+		{ .u.string = (char *)flags, .format = MPV_FORMAT_STRING },
+	};
+	mpv_node_list list = { .num = 2, .values = args };
+	mpv_node command = { .u.list = &list, .format = MPV_FORMAT_NODE_ARRAY };
+	memset(s, 0, sizeof(*s));
+	const int error = mpv_command_node(mpv, &command, &s->result);
+	if (error < 0)
+		return mpv_error_string(error);
+
+	const mpv_node *const width = find(&s->result, "w", MPV_FORMAT_INT64);
+	const mpv_node *const height = find(&s->result, "h", MPV_FORMAT_INT64);
+	const mpv_node *const stride = find(&s->result, "stride", MPV_FORMAT_INT64);
+	const mpv_node *const format = find(&s->result, "format", MPV_FORMAT_STRING);
+	const mpv_node *const data = find(&s->result, "data", MPV_FORMAT_BYTE_ARRAY);
+	if (width == NULL || height == NULL || stride == NULL || format == NULL || data == NULL) {
+		mpv_free_node_contents(&s->result);
+		return "screenshot-raw returned an unexpected result";
+	}
+	s->width = width->u.int64;
+	s->height = height->u.int64;
+	s->stride = stride->u.int64;
+	s->format = format->u.string;
+	s->data = data->u.ba->data;
+	s->size = data->u.ba->size;
+	return NULL;
+}
+
+void screenshot_free ( screenshot *const s ) {
+	mpv_free_node_contents(&s->result);
+}
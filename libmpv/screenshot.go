@@ -0,0 +1,64 @@
+package libmpv
+
+// #include <stdlib.h>
+// #include "screenshot.h"
+import "C"
+import (
+	"errors"
+	"fmt"
+	"image"
+	"unsafe"
+
+	"github.com/gen2brain/go-mpv"
+)
+
+// ScreenshotRaw grabs what m shows with the screenshot-raw command, which
+// hands the image over in memory rather than writing it to a file. flags
+// are those of the command, such as "video" or "window".
+func ScreenshotRaw(m *mpv.Mpv, flags string) (*image.RGBA, error) {
+	flagsC := C.CString(flags)
+	defer C.free(unsafe.Pointer(flagsC))
+
+	var s C.screenshot
+	if message := C.screenshot_raw(mpvHandle(m), flagsC, &s); message != nil {
+		return nil, errors.New(C.GoString(message))
+	}
+	defer C.screenshot_free(&s)
+
+	width, height, stride := int(s.width), int(s.height), int(s.stride)
+	if width <= 0 || height <= 0 || stride < width*4 || int(s.size) < stride*height {
+		return nil, errors.New("screenshot-raw returned a broken image")
+	}
+	// The channels are named in the order of the bytes of a pixel, and the
+	// padding byte of bgr0 and rgb0 is not alpha.
+	var red, blue int
+	var opaque bool
+	switch format := C.GoString(s.format); format {
+	case "bgr0":
+		red, blue, opaque = 2, 0, true
+	case "bgra":
+		red, blue = 2, 0
+	case "rgb0":
+		red, blue, opaque = 0, 2, true
+	case "rgba":
+		red, blue = 0, 2
+	default:
+		return nil, fmt.Errorf("screenshot-raw returned the unsupported format %q", format)
+	}
+
+	data := unsafe.Slice((*byte)(unsafe.Pointer(s.data)), stride*height)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		src, dst := data[y*stride:], img.Pix[y*img.Stride:]
+		for x := 0; x < width; x++ {
+			dst[4*x] = src[4*x+red]
+			dst[4*x+1] = src[4*x+1]
+			dst[4*x+2] = src[4*x+blue]
+			dst[4*x+3] = 0xff
+			if !opaque {
+				dst[4*x+3] = src[4*x+3]
+			}
+		}
+	}
+	return img, nil
+}
@@ -0,0 +1,21 @@
+#ifndef LIBMPV_SCREENSHOT_H
+#define LIBMPV_SCREENSHOT_H
+
+#include <stddef.h>
+#include <stdint.h>
+#include <mpv/client.h>
+
+/* screenshot is an image grabbed with screenshot-raw. Its fields point into
+   result, which screenshot_free releases. */
+typedef struct screenshot {
+	int64_t width, height, stride;
+	const char *format;
+	const unsigned char *data;
+	size_t size;
+	mpv_node result;
+} screenshot;
+
+extern const char *screenshot_raw ( mpv_handle *const mpv, const char *const flags, screenshot *const s );
+extern void screenshot_free ( screenshot *const s );
+
+#endif /* LIBMPV_SCREENSHOT_H */
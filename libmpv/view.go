@@ -0,0 +1,22 @@
+// Package libmpv binds the parts of libmpv that go-mpv leaves out.
+package libmpv
+
+import (
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// View is where a Renderer draws the video in one window.
+type View struct {
+	Window xlib.Window
+	// Width and Height are the size of the window.
+	Width, Height uint
+	// Visible is the part of the window the video covers. The rest of the
+	// window is black.
+	Visible geometry.Rect
+	// Corners are the points of the video that land on the top left, top
+	// right, bottom left and bottom right corners of Visible, as x and y in
+	// fractions of the width and height of the video. They can turn and
+	// mirror the video as well as crop it.
+	Corners [4][2]float64
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
-	"unsafe"
+	"time"
 
 	"github.com/gen2brain/go-mpv"
 	"github.com/zSnails/peruere/geometry"
@@ -15,127 +16,311 @@ import (
 )
 
 var (
-	videoFile string
-	geom      string
+	videoFile  string
+	geom       string
+	fit        string
+	spanning   bool
+	monitors   string
+	bezels     string
+	scale      string
+	rotate     string
+	outputs    outputFlags
+	mirror     bool
+	decodeOnce bool
+	lockstep   bool
+	rootEvery  time.Duration
+	input      string
+	clicks     bool
+	mask       string
+	radius     int
+	idle       time.Duration
+	dpms       string
 )
 
 func init() {
 	flag.StringVar(&videoFile, "file", "video.mp4", "the file to play as a wallpaper")
-	flag.StringVar(&geom, "geometry", "1920x1080+0+0", "the geometry for the background window")
+	flag.StringVar(&geom, "geometry", "", "the geometry of a single background window, in pixels or percentages of the screen, instead of one window per monitor")
+	flag.StringVar(&fit, "fit", "contain", "how the media fills the window: contain, cover, stretch, center or tile")
+	flag.BoolVar(&spanning, "span", false, "stretch one picture across every monitor, ignoring -geometry")
+	flag.StringVar(&monitors, "monitors", "", "comma separated geometries of the monitors to span, defaults to the monitors RandR reports")
+	flag.StringVar(&bezels, "bezels", "", "comma separated bezel sizes for each monitor, as all, h:v or l:r:t:b pixels")
+	flag.StringVar(&scale, "scale", "1", "device pixels per geometry pixel, or auto to follow the physical size of each monitor and then Xft.dpi")
+	flag.StringVar(&rotate, "rotate", "none", "rotate and flip the media, as steps like 90,flip-x")
+	flag.BoolVar(&mirror, "mirror", false, "show a copy of the same media in the window of every monitor")
+	flag.BoolVar(&decodeOnce, "decode-once", false, "with -mirror, decode the media once and draw it into every window through the mpv render API and EGL; experimental")
+	flag.BoolVar(&lockstep, "sync", true, "keep monitors that play the same file in step")
+	flag.DurationVar(&rootEvery, "root-pixmap", 30*time.Second, "how often to copy the wallpaper into the root window background for pseudo-transparent programs, or 0 to never")
+	flag.StringVar(&input, "input", "none", "comma separated geometries of the areas of each monitor that take clicks, relative to the monitor, or none or all")
+	flag.BoolVar(&clicks, "forward-clicks", false, "pass clicks on the -input areas on to the root window, for window manager root menus")
+	flag.StringVar(&mask, "mask", "", "a PNG image whose alpha channel cuts the wallpaper of each monitor to a shape")
+	flag.IntVar(&radius, "corner-radius", 0, "round the corners of the wallpaper of each monitor by this many pixels")
+	flag.DurationVar(&idle, "idle", 10*time.Minute, "pause playback once the user has been idle this long, or 0 to never")
+	flag.StringVar(&dpms, "dpms", "pause", "what to do while DPMS powers the display down: pause, unload the media, or none")
+	flag.Var(&outputs, "output", "settings for the monitors of one output, as <name|primary|index>:file=<media>,fit=<mode>,rotate=<steps>; may be repeated")
 	flag.Parse()
 }
 
+// relayoutDelay is how long the monitor layout has to stay the same before
+// wallpapers follow it, since RandR reports one change as several events.
+const relayoutDelay = 250 * time.Millisecond
+
 func main() {
 	runtime.LockOSThread()
 	defer runtime.UnlockOSThread()
-	attrs := xlib.SetWindowAttributes{
-		BackgroundPixmap: xlib.ParentRelative,
-		BackingStore:     xlib.Always,
-		SaveUnder:        xlib.False,
-		OverrideRedirect: xlib.True,
-	}
+	xlib.XInitThreads()
 	display := xlib.XOpenDisplay(nil)
+	if display == nil {
+		log.Fatalln("cannot open the X display")
+	}
 	defer xlib.XCloseDisplay(display)
+	xlib.XSetErrorHandler(func(err *xlib.XError) {
+		log.Println(err)
+	})
+	xlib.XSetIOErrorHandler(func(*xlib.Display) {
+		// The mpv instances go down with the process; there is nothing left
+		// for them to draw into anyway.
+		log.Println("lost the connection to the X server")
+		os.Exit(1)
+	})
 
 	root := xlib.XDefaultRootWindow(display)
-	width, height, xOffset, yOffset, err := geometry.ParseGeometry(geom)
+	screen := xlib.XDefaultScreenOfDisplay(display)
+	screenRect := geometry.Rect{
+		Width:  uint(xlib.XWidthOfScreen(screen)),
+		Height: uint(xlib.XHeightOfScreen(screen)),
+	}
+
+	cfg := config{file: videoFile, outputs: outputs}
+	var err error
+	if geom != "" {
+		spec, err := geometry.ParseSpec(geom)
+		if err != nil {
+			log.Fatalln("-geometry:", err)
+		}
+		cfg.spec = &spec
+	}
+	cfg.fit, err = geometry.ParseFitMode(fit)
 	if err != nil {
 		log.Fatalln(err)
 	}
-	window := xlib.XCreateWindow(display, root, xOffset, yOffset, width, height, 0, 0, xlib.InputOutput, nil, xlib.CWOverrideRedirect|xlib.CWBackingStore, &attrs)
-	defer xlib.XDestroyWindow(display, window)
-
-	xlib.XSetClassHint(
-		display,
-		window,
-		&xlib.ClassHint{
-			ResName:  "peruere",
-			ResClass: "peruere",
-		},
-	)
-
-	windowTypeDesktop := xlib.XInternAtom(display, "_NET_WM_WINDOW_TYPE_DESKTOP", xlib.False)
-	windowType := xlib.XInternAtom(display, "_NET_WM_WINDOW_TYPE", xlib.False)
-	xlib.XChangeProperty(display, window, windowType, xlib.XA_ATOM, 32, xlib.PropModeReplace, unsafe.Pointer(&windowTypeDesktop), 1)
-
-	motifWmHints := xlib.XInternAtom(display, "_MOTIF_WM_HINTS", xlib.False)
-	if motifWmHints != xlib.None {
-		prop := [5]int64{2, 0, 0, 0, 0}
-		xlib.XChangeProperty(display, window, motifWmHints, motifWmHints, 32, xlib.PropModeReplace, unsafe.Pointer(&prop), 5)
+	cfg.input, err = parseInput(input)
+	if err != nil {
+		log.Fatalln("-input:", err)
 	}
-
-	winLayer := xlib.XInternAtom(display, "_WIN_LAYER", xlib.False)
-	if winLayer != xlib.None {
-		layerZero := int64(0)
-		xlib.XChangeProperty(display, window, winLayer, xlib.XA_CARDINAL, 32, xlib.PropModeAppend, unsafe.Pointer(&layerZero), 1)
+	cfg.scale, err = parseScale(scale)
+	if err != nil {
+		log.Fatalln("-scale:", err)
 	}
-
-	wmState := xlib.XInternAtom(display, "_NET_WM_STATE", xlib.False)
-	if wmState != xlib.None {
-		stateBelow := xlib.XInternAtom(display, "_NET_WM_STATE_BELOW", xlib.False)
-		xlib.XChangeProperty(display, window, wmState, xlib.XA_ATOM, 32, xlib.PropModeAppend, unsafe.Pointer(&stateBelow), 1)
+	cfg.dpi = xftDPI(display)
+	if mask != "" {
+		cfg.mask, err = loadMask(mask)
+		if err != nil {
+			log.Fatalln("-mask:", err)
+		}
+	}
+	cfg.radius = radius
+	if (cfg.mask != nil || cfg.radius > 0) && !flagSet("root-pixmap") {
+		// The background shows through around a shaped wallpaper, so it
+		// is left to whatever set it unless asked otherwise.
+		rootEvery = 0
+	}
+	cfg.transform, err = geometry.ParseTransform(rotate)
+	if err != nil {
+		log.Fatalln(err)
+	}
+	switch dpms {
+	case "pause", "unload", "none":
+	default:
+		log.Fatalf("-dpms: unknown action %q\n", dpms)
+	}
+	if spanning {
+		if cfg.fit == geometry.Tile {
+			log.Fatalln("the tile fit mode cannot be used with -span")
+		}
+		cfg.span = true
+		cfg.monitors = monitors
+		cfg.bezels, err = parseBezels(bezels)
+		if err != nil {
+			log.Fatalln(err)
+		}
 	}
 
-	hints := xlib.WMHints{
-		Input: xlib.False,
+	if len(outputs) > 0 && (spanning || cfg.spec != nil) {
+		log.Fatalln("-output cannot be used with -span or -geometry")
 	}
-	xlib.XSetWMProperties(display, window, nil, nil, os.Args, len(os.Args), nil, &hints, nil)
 
-	wmDesktop := xlib.XInternAtom(display, "_NET_WM_DESKTOP", xlib.False)
-	stateSticky := xlib.XInternAtom(display, "_NET_WM_STATE_STICKY", xlib.False)
-	xlib.XChangeProperty(display, window, wmDesktop, xlib.XA_CARDINAL, 32, xlib.PropModeAppend, unsafe.Pointer(&stateSticky), 1)
+	if decodeOnce && !mirror {
+		log.Fatalln("-decode-once needs -mirror")
+	}
+	if mirror {
+		if spanning || cfg.spec != nil {
+			log.Fatalln("-mirror cannot be used with -span or -geometry")
+		}
+		for _, o := range outputs {
+			if o.file != "" {
+				log.Fatalln("-output", o.selector+": mirrored monitors share the file")
+			}
+		}
+		if decodeOnce {
+			if cfg.fit == geometry.Tile {
+				log.Fatalln("the tile fit mode cannot be used with -decode-once")
+			}
+			for _, o := range outputs {
+				if o.fit != nil && *o.fit == geometry.Tile {
+					log.Fatalln("-output", o.selector+": the tile fit mode cannot be used with -decode-once")
+				}
+			}
+			cfg.mirror = true
+		}
+	}
 
-	region := xlib.XCreateRegion()
-	if region != nil {
-		xlib.XShapeCombineRegion(display, window, xlib.ShapeInput, 0, 0, region, xlib.ShapeSet)
-		xlib.XDestroyRegion(region)
+	layouts, err := cfg.layouts(display, root, screenRect)
+	if err != nil {
+		log.Fatalln("-monitors:", err)
 	}
 
-	xlib.XLowerWindow(display, window)
+	xlib.XSelectInput(display, root, xlib.StructureNotifyMask)
+	if _, _, ok := xlib.XRRQueryExtension(display); ok {
+		xlib.XRRSelectInput(display, root, xlib.RRScreenChangeNotifyMask|xlib.RRCrtcChangeNotifyMask|xlib.RROutputChangeNotifyMask)
+	}
 
-	m := mpv.New()
-	defer m.TerminateDestroy()
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer cancel()
+	xEvents := xlib.Events(ctx, display)
+	defer func() {
+		// The pump uses the display until it closes xEvents, so wait for
+		// that before the display is closed.
+		cancel()
+		for range xEvents {
+		}
+	}()
 
-	if err := m.SetProperty("wid", mpv.FormatInt64, int(window)); err != nil {
-		log.Fatalln(err)
+	var eventMask int64
+	if clicks {
+		eventMask = xlib.ButtonPressMask | xlib.ButtonReleaseMask
 	}
-
-	if err := m.SetPropertyString("loop", "yes"); err != nil {
-		log.Fatalln(err)
+	d := newDesktop(ctx, display, root, eventMask)
+	defer d.close()
+	d.arrange(layouts)
+	if len(d.wallpapers) == 0 {
+		log.Fatalln("cannot create any wallpaper window")
 	}
 
-	if err := m.SetPropertyString("x11-bypass-compositor", "yes"); err != nil {
-		log.Fatalln(err)
+	var relayout, tick, publish, republish, idleCheck, powerCheck <-chan time.Time
+	if lockstep {
+		ticker := time.NewTicker(syncInterval)
+		defer ticker.Stop()
+		tick = ticker.C
 	}
-
-	if err := m.SetPropertyString("vo", "gpu"); err != nil {
-		log.Fatalln(err)
+	if rootEvery > 0 {
+		ticker := time.NewTicker(rootEvery)
+		defer ticker.Stop()
+		republish = ticker.C
 	}
-
-	if err := m.Initialize(); err != nil {
-		log.Fatalln(err)
+	if _, _, ok := xlib.XScreenSaverQueryExtension(display); ok {
+		xlib.XScreenSaverSelectInput(display, root, xlib.ScreenSaverNotifyMask)
+		if info, err := xlib.XScreenSaverQueryInfo(display, root); err == nil {
+			d.screenSaverChanged(info.State)
+		}
+		if idle > 0 {
+			ticker := time.NewTicker(idleCheckInterval)
+			defer ticker.Stop()
+			idleCheck = ticker.C
+		}
+	} else if idle > 0 {
+		log.Println("the X server has no MIT-SCREEN-SAVER extension, playback is never paused")
 	}
-
-	err = m.RequestLogMessages("trace")
-	if err := m.Command([]string{"loadfile", videoFile}); err != nil {
-		log.Fatalln(err)
+	if _, _, ok := xlib.DPMSQueryExtension(display); ok && xlib.DPMSCapable(display) && dpms != "none" {
+		d.checkPower(dpms == "unload")
+		ticker := time.NewTicker(powerCheckInterval)
+		defer ticker.Stop()
+		powerCheck = ticker.C
 	}
-
-	go func() {
-		for {
-			event := m.WaitEvent(10000)
-			switch event.EventID {
-			case mpv.EventLogMsg:
-				log.Printf("%v\n", event.LogMessage())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-xEvents:
+			if !ok {
+				return
+			}
+			switch event := event.(type) {
+			case *xlib.XDestroyWindowEvent:
+				if d.owns(event.Window) {
+					log.Println("a wallpaper window was destroyed")
+					return
+				}
+			case *xlib.XButtonEvent:
+				if d.owns(event.Window) {
+					if err := forwardClick(display, root, event); err != nil {
+						log.Println("cannot forward a click:", err)
+					}
+				}
+			case *xlib.XConfigureEvent:
+				if event.Window == root {
+					screenRect.Width, screenRect.Height = uint(event.Width), uint(event.Height)
+					relayout = time.After(relayoutDelay)
+				}
+			case *xlib.XScreenSaverNotifyEvent:
+				d.screenSaverChanged(event.State)
+			case *xlib.XRRScreenChangeNotifyEvent, *xlib.XRRCrtcChangeNotifyEvent, *xlib.XRROutputChangeNotifyEvent:
+				relayout = time.After(relayoutDelay)
+			}
+		case <-relayout:
+			relayout = nil
+			layouts, err := cfg.layouts(display, root, screenRect)
+			if err != nil {
+				log.Println(err)
+				continue
+			}
+			d.arrange(layouts)
+			if rootEvery > 0 {
+				publish = time.After(publishDelay)
+			}
+		case <-republish:
+			// Paused wallpapers still show what was last published.
+			if d.paused == 0 || !d.rootPaused {
+				publish = time.After(0)
+			}
+		case <-publish:
+			publish = nil
+			if d.publishing != nil {
+				// The frames may have changed since that publish started.
+				publish = time.After(publishDelay)
+				continue
+			}
+			d.publishRoot(screenRect)
+		case err := <-d.publishing:
+			d.published(err)
+		case <-powerCheck:
+			d.checkPower(dpms == "unload")
+		case <-idleCheck:
+			d.checkIdle(idle)
+		case <-tick:
+			d.sync()
+		case event := <-d.events:
+			switch event.id {
+			case mpv.EventShutdown:
+				return
+			case mpv.EventVideoReconfig:
+				event.w.reconfigure()
+				if rootEvery > 0 {
+					publish = time.After(publishDelay)
+				}
+			case mpv.EventPlaybackRestart:
+				if lockstep {
+					d.restarted(event.w)
+				}
 			}
 		}
-	}()
-
-	xlib.XStoreName(display, window, "peruere")
-	xlib.XMapWindow(display, window)
-	xlib.XFlush(display)
+	}
+}
 
-	sig := make(chan os.Signal, 1)
-	signal.Notify(sig, syscall.SIGTERM, syscall.SIGINT)
-	<-sig
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(name string) bool {
+	set := false
+	flag.Visit(func(f *flag.Flag) {
+		set = set || f.Name == name
+	})
+	return set
 }
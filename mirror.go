@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/libmpv"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// mirrorRegion is a monitor showing its own copy of mirrored media.
+type mirrorRegion struct {
+	rect      geometry.Rect
+	fit       geometry.FitMode
+	scale     geometry.Scale
+	transform geometry.Transform
+	radius    int
+}
+
+// mirrorLayout merges the layouts of several monitors into a single one, so
+// that one mpv instance decodes the media once and a renderer draws it into
+// the window of each monitor.
+func mirrorLayout(layouts []layout) layout {
+	var window geometry.Rect
+	for i, l := range layouts {
+		if i == 0 {
+			window = l.rect
+		} else {
+			window = window.Union(l.rect)
+		}
+	}
+	mirrored := layout{name: "mirror", rect: window}
+	for i, l := range layouts {
+		if i == 0 {
+			mirrored.file, mirrored.scale, mirrored.input, mirrored.mask = l.file, l.scale, l.input, l.mask
+		}
+		mirrored.mirror = append(mirrored.mirror, mirrorRegion{
+			rect:      l.rect,
+			fit:       l.fit,
+			scale:     l.scale,
+			transform: l.transform,
+			radius:    l.radius,
+		})
+	}
+	return mirrored
+}
+
+// applyMirror has r draw a copy of media of the source size into the window
+// of each region.
+func applyMirror(m *mpv.Mpv, r *libmpv.Renderer, source geometry.Rect, windows []xlib.Window, regions []mirrorRegion) error {
+	// The renderer has mpv draw the whole frame at its own size and places
+	// it itself.
+	if err := (videoProperties{}).apply(m); err != nil {
+		return err
+	}
+	r.SetViews(source, mirrorViews(source, windows, regions))
+	return nil
+}
+
+// mirrorViews places media of the source size in the window of each region.
+func mirrorViews(source geometry.Rect, windows []xlib.Window, regions []mirrorRegion) []libmpv.View {
+	views := make([]libmpv.View, len(regions))
+	for i, region := range regions {
+		target := geometry.Rect{Width: region.rect.Width, Height: region.rect.Height}
+		size := region.transform.Size(source)
+		p := geometry.FitScaled(size, target, region.fit, region.scale)
+		views[i] = libmpv.View{
+			Window:  windows[i],
+			Width:   target.Width,
+			Height:  target.Height,
+			Visible: p.Content.Intersect(target),
+		}
+		for j, corner := range [4][2]int{
+			{p.Crop.X, p.Crop.Y},
+			{p.Crop.Right(), p.Crop.Y},
+			{p.Crop.X, p.Crop.Bottom()},
+			{p.Crop.Right(), p.Crop.Bottom()},
+		} {
+			x, y := region.transform.SourcePoint(float64(corner[0])/float64(size.Width), float64(corner[1])/float64(size.Height))
+			views[i].Corners[j] = [2]float64{x, y}
+		}
+	}
+	return views
+}
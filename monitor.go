@@ -0,0 +1,108 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// monitor is a region of the screen shown by one or more RandR outputs.
+type monitor struct {
+	name    string
+	rect    geometry.Rect
+	primary bool
+	outputs []xlib.RROutput
+	// mmWidth and mmHeight are the physical size as shown, after rotation,
+	// or zero if unknown.
+	mmWidth, mmHeight uint
+}
+
+// listMonitors returns the active monitors of the screen, from RandR if it
+// knows any and from Xinerama otherwise, or nil if neither does.
+func listMonitors(display *xlib.Display, root xlib.Window) []monitor {
+	if monitors := randrMonitors(display, root); len(monitors) > 0 {
+		return monitors
+	}
+	return xineramaMonitors(display)
+}
+
+// randrMonitors returns the active monitors RandR reports. Monitors are taken
+// from RandR 1.5 when possible, otherwise each enabled CRTC is one monitor
+// named after its first output.
+func randrMonitors(display *xlib.Display, root xlib.Window) []monitor {
+	if _, _, ok := xlib.XRRQueryExtension(display); !ok {
+		return nil
+	}
+	resources := xlib.XRRGetScreenResourcesCurrent(display, root)
+	if resources == nil {
+		return nil
+	}
+	defer xlib.XRRFreeScreenResources(resources)
+
+	crtcs := make(map[xlib.RRCrtc]*xlib.CrtcInfo)
+	for _, crtc := range resources.Crtcs() {
+		if info := xlib.XRRGetCrtcInfo(display, resources, crtc); info != nil && info.Mode != 0 {
+			crtcs[crtc] = info
+		}
+	}
+	primary := xlib.XRRGetOutputPrimary(display, root)
+
+	var monitors []monitor
+	if major, minor, ok := xlib.XRRQueryVersion(display); ok && (major > 1 || minor >= 5) {
+		for _, info := range xlib.XRRGetMonitors(display, root, xlib.True) {
+			monitors = append(monitors, monitor{
+				name:     xlib.XGetAtomName(display, info.Name),
+				rect:     geometry.Rect{X: info.X, Y: info.Y, Width: info.Width, Height: info.Height},
+				primary:  info.Primary,
+				outputs:  info.Outputs,
+				mmWidth:  info.MMWidth,
+				mmHeight: info.MMHeight,
+			})
+		}
+		if len(monitors) > 0 {
+			return monitors
+		}
+	}
+
+	for _, crtc := range resources.Crtcs() {
+		info := crtcs[crtc]
+		if info == nil || len(info.Outputs) == 0 {
+			continue
+		}
+		m := monitor{
+			rect:    geometry.Rect{X: info.X, Y: info.Y, Width: info.Width, Height: info.Height},
+			outputs: info.Outputs,
+		}
+		for _, output := range info.Outputs {
+			m.primary = m.primary || output == primary
+		}
+		if output := xlib.XRRGetOutputInfo(display, resources, info.Outputs[0]); output != nil {
+			m.name = output.Name
+			m.mmWidth, m.mmHeight = output.MMWidth, output.MMHeight
+			if info.Rotation&(xlib.RR_Rotate_90|xlib.RR_Rotate_270) != 0 {
+				m.mmWidth, m.mmHeight = m.mmHeight, m.mmWidth
+			}
+		}
+		monitors = append(monitors, m)
+	}
+	return monitors
+}
+
+// xineramaMonitors returns the screens Xinerama combines, which have no
+// names. Screens with the same geometry, as cloned outputs
+// are reported, are listed once.
+func xineramaMonitors(display *xlib.Display) []monitor {
+	if _, _, ok := xlib.XineramaQueryExtension(display); !ok || !xlib.XineramaIsActive(display) {
+		return nil
+	}
+	var monitors []monitor
+	for _, screen := range xlib.XineramaQueryScreens(display) {
+		rect := geometry.Rect{X: screen.X, Y: screen.Y, Width: screen.Width, Height: screen.Height}
+		if slices.ContainsFunc(monitors, func(m monitor) bool { return m.rect == rect }) {
+			continue
+		}
+		monitors = append(monitors, monitor{rect: rect, primary: len(monitors) == 0})
+	}
+	return monitors
+}
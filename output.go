@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/zSnails/peruere/geometry"
+)
+
+// outputOptions are the settings given to the monitors matching a selector,
+// which is an output name such as "DP-1", "primary", or an index into the
+// monitors RandR reports. Fields that were not given are left unset and
+// fall back to the global flags.
+type outputOptions struct {
+	selector  string
+	file      string
+	fit       *geometry.FitMode
+	transform *geometry.Transform
+}
+
+// outputFlags collects repeated -output flags, each of the form
+//
+//	<selector>:<key>=<value>[,<key>=<value>...]
+//
+// where the keys are file, fit and rotate.
+type outputFlags []outputOptions
+
+func (f *outputFlags) String() string {
+	var list []string
+	for _, o := range *f {
+		list = append(list, o.selector)
+	}
+	return strings.Join(list, " ")
+}
+
+func (f *outputFlags) Set(value string) error {
+	selector, settings, ok := strings.Cut(value, ":")
+	if !ok || selector == "" {
+		return fmt.Errorf("%q: want <output>:<key>=<value>,...", value)
+	}
+	o := outputOptions{selector: selector}
+	for _, setting := range strings.Split(settings, ",") {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return fmt.Errorf("%q: want <key>=<value>", setting)
+		}
+		switch key {
+		case "file":
+			if value == "" {
+				return fmt.Errorf("%q: the file cannot be empty", setting)
+			}
+			o.file = value
+		case "fit":
+			mode, err := geometry.ParseFitMode(value)
+			if err != nil {
+				return err
+			}
+			o.fit = &mode
+		case "rotate":
+			t, err := geometry.ParseTransform(value)
+			if err != nil {
+				return err
+			}
+			o.transform = &t
+		default:
+			return fmt.Errorf("unknown output setting %q", key)
+		}
+	}
+	*f = append(*f, o)
+	return nil
+}
+
+// matches reports whether o applies to the monitor m at the given index.
+func (o *outputOptions) matches(m monitor, index int) bool {
+	switch o.selector {
+	case "primary":
+		return m.primary
+	case m.name:
+		return true
+	}
+	n, err := strconv.Atoi(o.selector)
+	return err == nil && n == index
+}
+
+// apply overrides the settings of l that o gives.
+func (o *outputOptions) apply(l *layout) {
+	if o.file != "" {
+		l.file = o.file
+	}
+	if o.fit != nil {
+		l.fit = *o.fit
+	}
+	if o.transform != nil {
+		l.transform = *o.transform
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+const (
+	// idleCheckInterval is how often the idle time is read while waiting
+	// for the user to go idle or come back.
+	idleCheckInterval = time.Second
+	// powerCheckInterval is how often the DPMS power level is read, since
+	// the server does not report changes to it.
+	powerCheckInterval = 5 * time.Second
+)
+
+// pauseReason is a set of reasons for playback to be paused.
+type pauseReason int
+
+const (
+	pauseIdle pauseReason = 1 << iota
+	pauseScreenSaver
+	pauseDisplayOff
+)
+
+var pauseReasonNames = [...]string{"the user is idle", "the screen saver is on", "the display is powered off"}
+
+func (r pauseReason) String() string {
+	for i, name := range pauseReasonNames {
+		if r == 1<<i {
+			return name
+		}
+	}
+	return "several reasons"
+}
+
+// pause adds reason to the reasons playback is paused for, or removes it,
+// and pauses or resumes every wallpaper when that starts or stops any.
+func (d *desktop) pause(reason pauseReason, paused bool) {
+	old := d.paused
+	if paused {
+		d.paused |= reason
+	} else {
+		d.paused &^= reason
+	}
+	switch {
+	case old == 0 && d.paused != 0:
+		log.Println("pausing, since", d.paused)
+	case old != 0 && d.paused == 0:
+		log.Println("resuming")
+	default:
+		return
+	}
+	for _, w := range d.wallpapers {
+		w.setPaused(d.paused != 0)
+	}
+}
+
+func (w *wallpaper) setPaused(paused bool) {
+	if err := w.m.SetProperty("pause", mpv.FormatFlag, paused); err != nil {
+		log.Printf("%s: %v\n", w.name, err)
+	}
+}
+
+// checkIdle pauses playback once the user has been idle for the given time
+// and resumes it on user activity.
+func (d *desktop) checkIdle(idle time.Duration) {
+	info, err := xlib.XScreenSaverQueryInfo(d.display, d.root)
+	if err != nil {
+		log.Println(err)
+		return
+	}
+	d.pause(pauseIdle, time.Duration(info.Idle)*time.Millisecond >= idle)
+}
+
+// screenSaverChanged pauses playback while the screen saver is on.
+func (d *desktop) screenSaverChanged(state int) {
+	d.pause(pauseScreenSaver, state == xlib.ScreenSaverOn || state == xlib.ScreenSaverCycle)
+}
+
+// checkPower pauses playback while DPMS has put the display in standby,
+// suspend or off, and resumes it once the display is on again. With unload
+// the media is also unloaded, freeing the decoders, and loaded again on wake.
+func (d *desktop) checkPower(unload bool) {
+	level, enabled, ok := xlib.DPMSInfo(d.display)
+	if !ok {
+		return
+	}
+	off := enabled && level != xlib.DPMSModeOn
+	if off == (d.paused&pauseDisplayOff != 0) {
+		return
+	}
+	if unload {
+		for _, w := range d.wallpapers {
+			if off {
+				w.unload()
+			} else {
+				w.reload()
+			}
+		}
+	}
+	d.pause(pauseDisplayOff, off)
+}
+
+func (w *wallpaper) unload() {
+	// Nothing is shown until reload, which the root pixmap goes by.
+	w.source = geometry.Rect{}
+	if err := w.m.Command([]string{"stop"}); err != nil {
+		log.Printf("%s: %v\n", w.name, err)
+	}
+}
+
+// reload loads the media of w again after unload. It is placed again once
+// mpv reports its size.
+func (w *wallpaper) reload() {
+	w.source = geometry.Rect{}
+	if err := w.m.Command([]string{"loadfile", w.file}); err != nil {
+		log.Printf("%s: %v\n", w.name, err)
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"errors"
+	"image"
+	"log"
+	"time"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/libmpv"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// publishDelay is how long after a wallpaper changed its frame is grabbed for
+// the root pixmap, giving mpv time to draw it.
+const publishDelay = 2 * time.Second
+
+// frame is what a wallpaper window shows.
+type frame struct {
+	rect  geometry.Rect
+	image image.Image
+}
+
+// grab is what it takes to grab the frames of a wallpaper away from the main
+// goroutine, which owns the wallpaper itself.
+type grab struct {
+	name     string
+	m        *mpv.Mpv
+	renderer *libmpv.Renderer
+	rects    []geometry.Rect
+}
+
+// frames grabs what the windows of the wallpaper currently show.
+func (g grab) frames() ([]frame, error) {
+	if g.renderer != nil {
+		images, err := g.renderer.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		var frames []frame
+		for i, rect := range g.rects {
+			if i < len(images) {
+				frames = append(frames, frame{rect, images[i]})
+			}
+		}
+		return frames, nil
+	}
+	img, err := libmpv.ScreenshotRaw(g.m, "window")
+	if err != nil {
+		return nil, err
+	}
+	return []frame{{g.rects[0], img}}, nil
+}
+
+// publishRoot starts painting the current frame of every wallpaper into a
+// pixmap the size of the screen and making it the root background. Reading
+// the frames back from mpv takes a while, so that happens on a goroutine of
+// its own, which reports on d.publishing; start no other publish until
+// then. Wallpapers that are stopped or show nothing yet are left out, and
+// with none left the root background stays as it is.
+func (d *desktop) publishRoot(screen geometry.Rect) {
+	var grabs []grab
+	for _, w := range d.wallpapers {
+		if w.source.Empty() {
+			continue
+		}
+		g := grab{name: w.name, m: w.m, renderer: w.renderer}
+		for _, s := range w.surfaces() {
+			g.rects = append(g.rects, s.rect)
+		}
+		grabs = append(grabs, g)
+	}
+	if len(grabs) == 0 {
+		return
+	}
+	d.rootPaused = d.paused != 0
+	done := make(chan error, 1)
+	d.publishing = done
+	go func() {
+		done <- paintRoot(grabs, screen)
+	}()
+}
+
+// published takes the result of the publish publishRoot started.
+func (d *desktop) published(err error) {
+	d.publishing = nil
+	if err != nil {
+		d.rootPaused = false
+		log.Println("cannot set the root pixmap:", err)
+	}
+}
+
+// waitPublish waits for a publish under way to finish, which must happen
+// before any wallpaper it grabs from is closed.
+func (d *desktop) waitPublish() {
+	if d.publishing != nil {
+		d.published(<-d.publishing)
+	}
+}
+
+// paintRoot paints the frames of the grabbed wallpapers into a new pixmap
+// and publishes it, following the Esetroot convention so that
+// pseudo-transparent programs show it too. A wallpaper whose frame cannot be
+// grabbed is left black.
+func paintRoot(grabs []grab, screen geometry.Rect) error {
+	var frames []frame
+	for _, g := range grabs {
+		grabbed, err := g.frames()
+		if err != nil {
+			log.Printf("%s: cannot grab the frame for the root pixmap: %v\n", g.name, err)
+			continue
+		}
+		frames = append(frames, grabbed...)
+	}
+
+	// The pixmap has to outlive the connection it was made on, so it is
+	// made on a connection of its own that is closed with RetainPermanent
+	// rather than on the main one, whose windows must go when peruere exits.
+	conn := xlib.XOpenDisplay(nil)
+	if conn == nil {
+		return errors.New("cannot open a connection for the root pixmap")
+	}
+	screenNumber := xlib.XDefaultScreen(conn)
+	root := xlib.XDefaultRootWindow(conn)
+	depth := xlib.XDefaultDepth(conn, screenNumber)
+	visual := xlib.XDefaultVisual(conn, screenNumber)
+
+	pixmap := xlib.XCreatePixmap(conn, root, screen.Width, screen.Height, depth)
+	gc := xlib.XCreateGC(conn, xlib.Window(pixmap), 0, nil)
+	xlib.XSetForeground(conn, gc, 0)
+	xlib.XFillRectangle(conn, xlib.Window(pixmap), gc, 0, 0, screen.Width, screen.Height)
+	for _, f := range frames {
+		ximage, err := xlib.CreateImage(conn, visual, depth, f.image)
+		if err != nil {
+			xlib.XFreeGC(conn, gc)
+			xlib.XFreePixmap(conn, pixmap)
+			xlib.XCloseDisplay(conn)
+			return err
+		}
+		size := f.image.Bounds()
+		xlib.XPutImage(conn, xlib.Window(pixmap), gc, ximage, 0, 0, f.rect.X-screen.X, f.rect.Y-screen.Y, uint(size.Dx()), uint(size.Dy()))
+		xlib.XDestroyImage(ximage)
+	}
+	xlib.XFreeGC(conn, gc)
+
+	err := setRootPixmap(conn, root, pixmap)
+	xlib.XSetCloseDownMode(conn, xlib.RetainPermanent)
+	xlib.XCloseDisplay(conn)
+	return err
+}
+
+// setRootPixmap publishes pixmap in _XROOTPMAP_ID and ESETROOT_PMAP_ID and
+// sets it as the background of root. Whoever set the previous pixmap kept it
+// alive past its exit, so when both properties still name it, its resources
+// are freed first.
+func setRootPixmap(display *xlib.Display, root xlib.Window, pixmap xlib.Pixmap) error {
+	rootAtom := xlib.XInternAtom(display, "_XROOTPMAP_ID", xlib.False)
+	esetrootAtom := xlib.XInternAtom(display, "ESETROOT_PMAP_ID", xlib.False)
+
+	old, err := xlib.GetLongList(display, root, rootAtom, xlib.XA_PIXMAP)
+	if err == nil && len(old) == 1 {
+		esetroot, err := xlib.GetLongList(display, root, esetrootAtom, xlib.XA_PIXMAP)
+		if err == nil && len(esetroot) == 1 && esetroot[0] == old[0] {
+			xlib.XKillClient(display, old[0])
+		}
+	}
+
+	value := []uint64{uint64(pixmap)}
+	serial := xlib.BeginCheck(display)
+	xlib.SetLongList(display, root, rootAtom, xlib.XA_PIXMAP, xlib.PropModeReplace, value)
+	xlib.SetLongList(display, root, esetrootAtom, xlib.XA_PIXMAP, xlib.PropModeReplace, value)
+	if err := xlib.CheckRequest(display, serial); err != nil {
+		return err
+	}
+	xlib.XSetWindowBackgroundPixmap(display, root, pixmap)
+	xlib.XClearWindow(display, root)
+	return nil
+}
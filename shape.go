@@ -0,0 +1,148 @@
+package main
+
+import (
+	"image"
+	"image/draw"
+	"image/png"
+	"log"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// parseInput parses the areas of a wallpaper window that accept input, as a
+// comma separated list of geometries relative to the window. "none" leaves
+// the whole window click-through and "all" makes all of it accept input.
+func parseInput(list string) ([]geometry.Spec, error) {
+	switch list {
+	case "", "none":
+		return nil, nil
+	case "all":
+		// A geometry without any field covers the window.
+		return []geometry.Spec{{}}, nil
+	}
+	var specs []geometry.Spec
+	for _, field := range strings.Split(list, ",") {
+		spec, err := geometry.ParseSpec(field)
+		if err != nil {
+			return nil, err
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// setInputShape makes only the input areas of the window accept input, so
+// that clicks anywhere else go through to the root window. The areas are
+// placed on each monitor the window covers.
+func setInputShape(display *xlib.Display, window xlib.Window, l layout) {
+	if _, _, ok := xlib.XShapeQueryExtension(display); !ok {
+		log.Println("the X server has no Shape extension, the wallpaper takes every click")
+		return
+	}
+	region := xlib.XCreateRegion()
+	if region == nil {
+		return
+	}
+	defer xlib.XDestroyRegion(region)
+	for _, monitor := range l.monitors() {
+		for _, spec := range l.input {
+			r := spec.ResolveScaled(monitor, l.scale).Intersect(monitor)
+			xlib.XUnionRectWithRegion(xlib.XRectangle{X: r.X, Y: r.Y, Width: r.Width, Height: r.Height}, region, region)
+		}
+	}
+	xlib.XShapeCombineRegion(display, window, xlib.ShapeInput, 0, 0, region, xlib.ShapeSet)
+}
+
+// monitors returns the monitors l covers, relative to its window.
+func (l layout) monitors() []geometry.Rect {
+	var monitors []geometry.Rect
+	switch {
+	case l.span != nil:
+		for _, region := range l.span.Regions {
+			monitors = append(monitors, region.Monitor)
+		}
+	default:
+		monitors = append(monitors, geometry.Rect{Width: l.rect.Width, Height: l.rect.Height})
+	}
+	return monitors
+}
+
+// sameShape reports whether windows of l and o have the same shapes, which
+// only depend on the size of the window and where its monitors are in it.
+func (l layout) sameShape(o layout) bool {
+	return l.rect.Width == o.rect.Width && l.rect.Height == o.rect.Height && slices.Equal(l.monitors(), o.monitors()) &&
+		slices.Equal(l.input, o.input) && l.scale == o.scale && l.mask == o.mask && l.radius == o.radius
+}
+
+// loadMask reads a PNG image whose alpha channel gives the shape of the
+// wallpaper on each monitor. Only the alpha channel is kept, which is read
+// for every pixel of the windows.
+func loadMask(path string) (*image.Alpha, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	img, err := png.Decode(file)
+	if err != nil {
+		return nil, err
+	}
+	mask := image.NewAlpha(img.Bounds())
+	draw.Draw(mask, mask.Bounds(), img, img.Bounds().Min, draw.Src)
+	return mask, nil
+}
+
+// setBoundingShape cuts the window of l to its mask, stretched over each of
+// its monitors, and rounds the corners of every monitor by its radius. The
+// window keeps its rectangular shape when l has neither.
+func setBoundingShape(display *xlib.Display, window xlib.Window, l layout) {
+	if l.mask == nil && l.radius == 0 {
+		return
+	}
+	if _, _, ok := xlib.XShapeQueryExtension(display); !ok {
+		log.Println("the X server has no Shape extension, the wallpaper stays rectangular")
+		return
+	}
+
+	width, height := int(l.rect.Width), int(l.rect.Height)
+	stride := (width + 7) / 8
+	bitmap := make([]byte, stride*height)
+	for _, monitor := range l.monitors() {
+		area := monitor.Intersect(geometry.Rect{Width: l.rect.Width, Height: l.rect.Height})
+		for y := area.Y; y < area.Bottom(); y++ {
+			for x := area.X; x < area.Right(); x++ {
+				if shaped(l, x-monitor.X, y-monitor.Y, int(monitor.Width), int(monitor.Height)) {
+					bitmap[y*stride+x/8] |= 1 << (x % 8)
+				}
+			}
+		}
+	}
+
+	pixmap := xlib.XCreateBitmapFromData(display, window, bitmap, l.rect.Width, l.rect.Height)
+	xlib.XShapeCombineMask(display, window, xlib.ShapeBounding, 0, 0, pixmap, xlib.ShapeSet)
+	xlib.XFreePixmap(display, pixmap)
+}
+
+// shaped reports whether the pixel at x, y of a monitor of the given size is
+// inside the shape of l.
+func shaped(l layout, x, y, width, height int) bool {
+	if r := float64(l.radius); r > 0 {
+		// Distance from the center of the pixel to the rectangle the
+		// corner circles are centered on.
+		cx, cy := float64(x)+0.5, float64(y)+0.5
+		dx := max(r-cx, cx-(float64(width)-r), 0)
+		dy := max(r-cy, cy-(float64(height)-r), 0)
+		if dx*dx+dy*dy > r*r {
+			return false
+		}
+	}
+	if l.mask != nil {
+		b := l.mask.Rect
+		return l.mask.Pix[l.mask.PixOffset(b.Min.X+x*b.Dx()/width, b.Min.Y+y*b.Dy()/height)] >= 0x80
+	}
+	return true
+}
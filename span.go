@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/geometry"
+)
+
+// parseMonitors parses a comma separated list of geometries, each resolved
+// against the screen.
+func parseMonitors(list string, screen geometry.Rect, scale geometry.Scale) ([]geometry.Rect, error) {
+	var monitors []geometry.Rect
+	for _, field := range strings.Split(list, ",") {
+		spec, err := geometry.ParseSpec(field)
+		if err != nil {
+			return nil, err
+		}
+		monitors = append(monitors, spec.ResolveScaled(screen, scale))
+	}
+	return monitors, nil
+}
+
+// parseBezels parses a comma separated list of bezels, one per monitor.
+func parseBezels(list string) ([]geometry.Bezel, error) {
+	if list == "" {
+		return nil, nil
+	}
+	var bezels []geometry.Bezel
+	for _, field := range strings.Split(list, ",") {
+		bezel, err := geometry.ParseBezel(field)
+		if err != nil {
+			return nil, err
+		}
+		bezels = append(bezels, bezel)
+	}
+	return bezels, nil
+}
+
+// applySpan fits media of the source size into the span canvas and cuts it
+// into one piece per monitor, laid out over the span window.
+func applySpan(m *mpv.Mpv, source geometry.Rect, span geometry.Span, mode geometry.FitMode, scale geometry.Scale, transform geometry.Transform) error {
+	p := geometry.FitScaled(transform.Size(source), span.Canvas, mode, scale)
+	v := videoProperties{filter: "lavfi=[" + transformGraph(transform) + spanGraph(p, span) + "]"}
+	return v.apply(m)
+}
+
+// spanGraph builds a libavfilter graph that draws the fitted media onto the
+// canvas, then moves the part behind each monitor to its place in the window.
+func spanGraph(p geometry.Placement, span geometry.Span) string {
+	var graph strings.Builder
+	visible := p.Content.Intersect(span.Canvas)
+	fmt.Fprintf(&graph, "scale=%d:%d,crop=%d:%d:%d:%d,pad=%d:%d:%d:%d",
+		p.Content.Width, p.Content.Height,
+		visible.Width, visible.Height, visible.X-p.Content.X, visible.Y-p.Content.Y,
+		span.Canvas.Width, span.Canvas.Height, visible.X, visible.Y)
+
+	n := len(span.Regions)
+	if n > 1 {
+		fmt.Fprintf(&graph, ",split=%d", n)
+		for i := 0; i < n; i++ {
+			fmt.Fprintf(&graph, "[s%d]", i)
+		}
+		graph.WriteByte(';')
+	}
+	for i, region := range span.Regions {
+		if n > 1 {
+			fmt.Fprintf(&graph, "[s%d]", i)
+		} else {
+			graph.WriteByte(',')
+		}
+		fmt.Fprintf(&graph, "crop=%d:%d:%d:%d",
+			region.Canvas.Width, region.Canvas.Height, region.Canvas.X, region.Canvas.Y)
+		if i == 0 {
+			fmt.Fprintf(&graph, ",pad=%d:%d:%d:%d",
+				span.Window.Width, span.Window.Height, region.Monitor.X, region.Monitor.Y)
+		}
+		if n > 1 {
+			fmt.Fprintf(&graph, "[m%d];", i)
+		}
+	}
+	for i := 1; i < n; i++ {
+		if i == 1 {
+			graph.WriteString("[m0]")
+		} else {
+			fmt.Fprintf(&graph, "[b%d]", i-1)
+		}
+		fmt.Fprintf(&graph, "[m%d]overlay=%d:%d", i, span.Regions[i].Monitor.X, span.Regions[i].Monitor.Y)
+		if i < n-1 {
+			fmt.Fprintf(&graph, "[b%d];", i)
+		}
+	}
+	return graph.String()
+}
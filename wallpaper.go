@@ -0,0 +1,347 @@
+package main
+
+import (
+	"context"
+	"image"
+	"log"
+	"os"
+	"slices"
+
+	"github.com/gen2brain/go-mpv"
+	"github.com/zSnails/peruere/ewmh"
+	"github.com/zSnails/peruere/geometry"
+	"github.com/zSnails/peruere/libmpv"
+	"github.com/zSnails/peruere/xlib"
+)
+
+// layout is where a wallpaper window goes and how its media is placed.
+type layout struct {
+	// name identifies the wallpaper across changes of the monitor layout,
+	// so that it is moved rather than recreated.
+	name      string
+	file      string
+	rect      geometry.Rect
+	span      *geometry.Span
+	mirror    []mirrorRegion
+	fit       geometry.FitMode
+	scale     geometry.Scale
+	transform geometry.Transform
+	// input lists the areas of the window that accept input.
+	input []geometry.Spec
+	// mask and radius cut the window of each monitor to a shape, from the
+	// alpha channel of an image and by rounding its corners.
+	mask   *image.Alpha
+	radius int
+}
+
+func (l layout) equal(o layout) bool {
+	if (l.span == nil) != (o.span == nil) {
+		return false
+	}
+	if l.span != nil && (l.span.Window != o.span.Window || l.span.Canvas != o.span.Canvas || !slices.Equal(l.span.Regions, o.span.Regions)) {
+		return false
+	}
+	return l.name == o.name && l.file == o.file && l.rect == o.rect && slices.Equal(l.mirror, o.mirror) && slices.Equal(l.input, o.input) &&
+		l.fit == o.fit && l.scale == o.scale && l.transform == o.transform && l.mask == o.mask && l.radius == o.radius
+}
+
+// surfaces returns a layout for each window of l: one for each monitor when
+// l is mirrored, or l itself.
+func (l layout) surfaces() []layout {
+	if l.mirror == nil {
+		return []layout{l}
+	}
+	surfaces := make([]layout, len(l.mirror))
+	for i, region := range l.mirror {
+		surfaces[i] = layout{
+			name:      l.name,
+			file:      l.file,
+			rect:      region.rect,
+			fit:       region.fit,
+			scale:     region.scale,
+			transform: region.transform,
+			input:     l.input,
+			mask:      l.mask,
+			radius:    region.radius,
+		}
+	}
+	return surfaces
+}
+
+// wallpaper is media played with its own mpv instance in desktop windows.
+// mpv draws into the window itself, except for mirrored layouts, which have
+// a window for each surface that a renderer draws into.
+type wallpaper struct {
+	layout
+	display  *xlib.Display
+	windows  []xlib.Window
+	m        *mpv.Mpv
+	renderer *libmpv.Renderer
+	source   geometry.Rect
+	speed    float64
+	cancel   context.CancelFunc
+	done     <-chan struct{}
+	// rejoin marks a wallpaper to start over with the one it follows.
+	rejoin bool
+}
+
+// wallpaperEvent is an mpv event of one of the wallpapers.
+type wallpaperEvent struct {
+	w  *wallpaper
+	id mpv.EventID
+}
+
+// newWallpaper creates the wallpaper windows for l that report the X events
+// in eventMask, starts playing its file and sends the mpv events of the
+// wallpaper on events until it is closed.
+func newWallpaper(ctx context.Context, display *xlib.Display, root xlib.Window, l layout, eventMask int64, events chan<- wallpaperEvent) (*wallpaper, error) {
+	w := &wallpaper{layout: l, display: display, speed: 1}
+	for _, s := range l.surfaces() {
+		window, err := newWindow(display, root, s)
+		if err != nil {
+			w.destroyWindows()
+			return nil, err
+		}
+		w.windows = append(w.windows, window)
+	}
+
+	var err error
+	if l.mirror != nil {
+		w.m, w.renderer, err = newPlayer(display, xlib.None, l.file)
+	} else {
+		w.m, _, err = newPlayer(display, w.windows[0], l.file)
+	}
+	if err != nil {
+		w.destroyWindows()
+		return nil, err
+	}
+
+	for _, window := range w.windows {
+		xlib.XStoreName(display, window, "peruere")
+		xlib.XSelectInput(display, window, xlib.StructureNotifyMask|eventMask)
+		xlib.XMapWindow(display, window)
+	}
+
+	ctx, w.cancel = context.WithCancel(ctx)
+	w.done = w.pump(ctx, events)
+	return w, nil
+}
+
+// newWindow creates an unmapped desktop window for the surface s.
+func newWindow(display *xlib.Display, root xlib.Window, s layout) (xlib.Window, error) {
+	attrs := xlib.SetWindowAttributes{
+		BackgroundPixmap: xlib.ParentRelative,
+		BackingStore:     xlib.Always,
+		SaveUnder:        xlib.False,
+		OverrideRedirect: xlib.True,
+	}
+	serial := xlib.BeginCheck(display)
+	window := xlib.XCreateWindow(display, root, s.rect.X, s.rect.Y, s.rect.Width, s.rect.Height, 0, 0, xlib.InputOutput, nil, xlib.CWOverrideRedirect|xlib.CWBackingStore, &attrs)
+	if err := xlib.CheckRequest(display, serial); err != nil {
+		return xlib.None, err
+	}
+
+	xlib.XSetClassHint(
+		display,
+		window,
+		&xlib.ClassHint{
+			ResName:  "peruere",
+			ResClass: "peruere",
+		},
+	)
+
+	hints := xlib.WMHints{
+		Input: xlib.False,
+	}
+	xlib.XSetWMProperties(display, window, nil, nil, os.Args, len(os.Args), nil, &hints, nil)
+
+	ewmh.SetWindowType(display, window, ewmh.WindowTypeDesktop)
+	ewmh.SetMotifHints(display, window, ewmh.NoDecorations)
+	ewmh.SetLayer(display, window, 0)
+	ewmh.SetWMState(display, window, ewmh.StateBelow, ewmh.StateSticky, ewmh.StateSkipTaskbar, ewmh.StateSkipPager)
+	ewmh.SetDesktop(display, window, ewmh.AllDesktops)
+	ewmh.SetPID(display, window, os.Getpid())
+	ewmh.SetWMName(display, window, "peruere")
+
+	setInputShape(display, window, s)
+	setBoundingShape(display, window, s)
+
+	xlib.XLowerWindow(display, window)
+	return window, nil
+}
+
+// newPlayer starts an mpv instance playing file in window. Without a window,
+// mpv draws through the render API instead, for the returned renderer to
+// place in windows of its own.
+func newPlayer(display *xlib.Display, window xlib.Window, file string) (*mpv.Mpv, *libmpv.Renderer, error) {
+	m := mpv.New()
+	// The video output is made while initializing and kept until the
+	// instance is terminated, so that it touches the X error handler only
+	// then: see withVideoOutput.
+	options := [][2]string{{"loop", "yes"}, {"x11-bypass-compositor", "yes"}, {"vo", "gpu"}, {"force-window", "immediate"}}
+	if window == xlib.None {
+		// Hardware decoders hand their frames to the renderer on the GPU,
+		// where they stay until they are drawn. The libmpv video output
+		// leaves the X error handler alone.
+		options = [][2]string{{"loop", "yes"}, {"vo", "libmpv"}, {"hwdec", "auto-safe"}}
+	} else if err := m.SetProperty("wid", mpv.FormatInt64, int(window)); err != nil {
+		m.TerminateDestroy()
+		return nil, nil, err
+	}
+
+	for _, option := range options {
+		if err := m.SetPropertyString(option[0], option[1]); err != nil {
+			m.TerminateDestroy()
+			return nil, nil, err
+		}
+	}
+
+	var err error
+	withVideoOutput(display, func() {
+		if err = m.Initialize(); err != nil {
+			m.TerminateDestroy()
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := m.RequestLogMessages("trace"); err != nil {
+		log.Println(err)
+	}
+
+	var renderer *libmpv.Renderer
+	if window == xlib.None {
+		renderer, err = libmpv.NewRenderer(m)
+		if err != nil {
+			m.TerminateDestroy()
+			return nil, nil, err
+		}
+	}
+	if err := m.Command([]string{"loadfile", file}); err != nil {
+		if renderer != nil {
+			renderer.Close()
+		}
+		withVideoOutput(display, m.TerminateDestroy)
+		return nil, nil, err
+	}
+	return m, renderer, nil
+}
+
+// withVideoOutput runs f, which starts or stops the video output of an mpv
+// instance. Xlib keeps one error handler for the whole process, which the
+// X11 video outputs replace when they start and reset to Xlib's default,
+// which exits on the first error, when they stop. The display stays locked
+// until ours is back, so that no error on it meets Xlib's handler.
+func withVideoOutput(display *xlib.Display, f func()) {
+	xlib.XLockDisplay(display)
+	defer xlib.XUnlockDisplay(display)
+	f()
+	if xlib.ReinstallErrorHandlers() {
+		log.Println("took back the X error handler from mpv")
+	}
+}
+
+// pump starts a goroutine that waits for mpv events and sends them on events
+// until ctx is cancelled, logging log messages on the way since their data
+// does not outlive the next wait. The returned channel is closed once the
+// goroutine is done with the mpv instance; wake it up after cancelling ctx
+// to get there quickly.
+func (w *wallpaper) pump(ctx context.Context, events chan<- wallpaperEvent) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ctx.Err() == nil {
+			event := w.m.WaitEvent(-1)
+			switch event.EventID {
+			case mpv.EventNone:
+				continue
+			case mpv.EventLogMsg:
+				log.Printf("%s: %v\n", w.name, event.LogMessage())
+				continue
+			}
+			select {
+			case events <- wallpaperEvent{w, event.EventID}:
+			case <-ctx.Done():
+			}
+			if event.EventID == mpv.EventShutdown {
+				return
+			}
+		}
+	}()
+	return done
+}
+
+// reconfigure places the media again once its size is known or changed.
+func (w *wallpaper) reconfigure() {
+	size, err := mediaSize(w.m)
+	if err != nil || size == w.source {
+		return
+	}
+	w.source = size
+	w.refit()
+}
+
+func (w *wallpaper) refit() {
+	if w.source.Empty() {
+		return
+	}
+	var err error
+	switch {
+	case w.span != nil:
+		err = applySpan(w.m, w.source, *w.span, w.fit, w.scale, w.transform)
+	case w.mirror != nil:
+		err = applyMirror(w.m, w.renderer, w.source, w.windows, w.mirror)
+	default:
+		err = applyFit(w.m, w.source, w.rect, w.fit, w.scale, w.transform)
+	}
+	if err != nil {
+		log.Printf("%s: %v\n", w.name, err)
+	}
+}
+
+// canMove reports whether w can take the layout l, which needs as many
+// windows as w has.
+func (w *wallpaper) canMove(l layout) bool {
+	return (w.mirror == nil) == (l.mirror == nil) && len(l.surfaces()) == len(w.windows)
+}
+
+// move gives w a new layout, moving its windows, switching to another file if
+// needed and placing the media again.
+func (w *wallpaper) move(l layout) {
+	old := w.layout.surfaces()
+	w.layout = l
+	for i, s := range l.surfaces() {
+		if old[i].rect != s.rect {
+			xlib.XMoveResizeWindow(w.display, w.windows[i], s.rect.X, s.rect.Y, s.rect.Width, s.rect.Height)
+		}
+		if !old[i].sameShape(s) {
+			setInputShape(w.display, w.windows[i], s)
+			setBoundingShape(w.display, w.windows[i], s)
+		}
+	}
+	if old[0].file != l.file {
+		w.reload()
+		return
+	}
+	w.refit()
+}
+
+// close stops the mpv instance of w and destroys its windows.
+func (w *wallpaper) close() {
+	w.cancel()
+	w.m.Wakeup()
+	<-w.done
+	if w.renderer != nil {
+		w.renderer.Close()
+	}
+	withVideoOutput(w.display, w.m.TerminateDestroy)
+	w.destroyWindows()
+}
+
+func (w *wallpaper) destroyWindows() {
+	for _, window := range w.windows {
+		xlib.XDestroyWindow(w.display, window)
+	}
+	w.windows = nil
+}
@@ -28,12 +28,28 @@ const (
 
 	XA_ATOM         = C.XA_ATOM
 	XA_CARDINAL     = C.XA_CARDINAL
+	XA_INTEGER      = C.XA_INTEGER
+	XA_PIXMAP       = C.XA_PIXMAP
 	XA_STRING       = C.XA_STRING
+	XA_WINDOW       = C.XA_WINDOW
+	XA_WM_NAME      = C.XA_WM_NAME
 	PropModeReplace = C.PropModeReplace
 	PropModeAppend  = C.PropModeAppend
+	PropModePrepend = C.PropModePrepend
 
-	ShapeInput = C.ShapeInput
-	ShapeSet   = C.ShapeSet
+	ShapeBounding  = C.ShapeBounding
+	ShapeClip      = C.ShapeClip
+	ShapeInput     = C.ShapeInput
+	ShapeSet       = C.ShapeSet
+	ShapeUnion     = C.ShapeUnion
+	ShapeIntersect = C.ShapeIntersect
+	ShapeSubtract  = C.ShapeSubtract
+	ShapeInvert    = C.ShapeInvert
+
+	Unsorted = C.Unsorted
+	YSorted  = C.YSorted
+	YXSorted = C.YXSorted
+	YXBanded = C.YXBanded
 
 	Success           = C.Success
 	BadRequest        = C.BadRequest
@@ -0,0 +1,38 @@
+package xlib
+
+// #include <X11/Xlib.h>
+// #include <X11/extensions/dpms.h>
+import "C"
+
+const (
+	DPMSModeOn      = uint16(C.DPMSModeOn)
+	DPMSModeStandby = uint16(C.DPMSModeStandby)
+	DPMSModeSuspend = uint16(C.DPMSModeSuspend)
+	DPMSModeOff     = uint16(C.DPMSModeOff)
+)
+
+func DPMSQueryExtension(display *Display) (eventBase, errorBase int, ok bool) {
+	displayC := (*C.Display)(display)
+	var eventBaseC, errorBaseC C.int
+	if C.DPMSQueryExtension(displayC, &eventBaseC, &errorBaseC) == 0 {
+		return 0, 0, false
+	}
+	return int(eventBaseC), int(errorBaseC), true
+}
+
+func DPMSCapable(display *Display) bool {
+	displayC := (*C.Display)(display)
+	return C.DPMSCapable(displayC) != 0
+}
+
+// DPMSInfo returns the current power level of the display and whether DPMS
+// is enabled. ok is false if the request failed.
+func DPMSInfo(display *Display) (powerLevel uint16, enabled bool, ok bool) {
+	displayC := (*C.Display)(display)
+	var powerLevelC C.CARD16
+	var stateC C.BOOL
+	if C.DPMSInfo(displayC, &powerLevelC, &stateC) == 0 {
+		return 0, false, false
+	}
+	return uint16(powerLevelC), stateC != 0, true
+}
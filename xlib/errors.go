@@ -0,0 +1,213 @@
+package xlib
+
+// #include <stdlib.h>
+// #include <X11/Xlib.h>
+// #include "xlib.h"
+import "C"
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sync"
+)
+
+// XError is an error the X server reported for one of our requests.
+type XError struct {
+	Display     *Display
+	Serial      uint64
+	ErrorCode   int
+	RequestCode int
+	MinorCode   int
+	ResourceID  uint64
+	Text        string
+}
+
+func (e *XError) Error() string {
+	text := e.Text
+	if text == "" {
+		text = ErrorString(e.ErrorCode)
+	}
+	return fmt.Sprintf("X error %s: request %d.%d, resource %#x, serial %d",
+		text, e.RequestCode, e.MinorCode, e.ResourceID, e.Serial)
+}
+
+var errorHandlers struct {
+	sync.Mutex
+	installed bool
+	// checks counts the checks under way on each display by the serial
+	// they started at, and pending holds the errors of the requests made
+	// since the first of them. Errors of other requests are only passed to
+	// the handler.
+	checks    map[*Display]map[uint64]int
+	pending   map[*Display][]*XError
+	handler   func(*XError)
+	ioHandler func(*Display)
+}
+
+// installErrorHandlers replaces the Xlib handlers, which print the error and
+// exit, with ours. It is safe to call more than once.
+func installErrorHandlers() {
+	if errorHandlers.installed {
+		return
+	}
+	errorHandlers.installed = true
+	C.xlib_set_error_handlers()
+}
+
+// ReinstallErrorHandlers puts the Go handlers back in place and reports
+// whether anything had replaced them. Xlib keeps one error handler for the
+// whole process: the X11 video outputs of mpv install their own when they
+// start and reset it to Xlib's default, which exits on the first error, when
+// they stop. Call it right after starting or stopping anything that may have
+// done either, while holding the lock of the displays that must not meet
+// Xlib's handler in between. It does nothing before XSetErrorHandler or
+// XSetIOErrorHandler.
+func ReinstallErrorHandlers() bool {
+	errorHandlers.Lock()
+	installed := errorHandlers.installed
+	errorHandlers.Unlock()
+	return installed && C.xlib_set_error_handlers() != 0
+}
+
+// XSetErrorHandler installs a Go handler that is called with every error the
+// X server reports. Errors are also kept so that CheckRequest can return them
+// for the request that caused them. The handler runs inside Xlib and must not
+// make requests itself.
+func XSetErrorHandler(handler func(*XError)) {
+	errorHandlers.Lock()
+	defer errorHandlers.Unlock()
+	installErrorHandlers()
+	errorHandlers.handler = handler
+}
+
+// XSetIOErrorHandler installs a Go handler that is called when the connection
+// to the X server breaks. Xlib exits the process as soon as the handler
+// returns, so it must finish shutting down before it does.
+func XSetIOErrorHandler(handler func(*Display)) {
+	errorHandlers.Lock()
+	defer errorHandlers.Unlock()
+	installErrorHandlers()
+	errorHandlers.ioHandler = handler
+}
+
+// BeginCheck starts keeping the errors the X server reports for requests
+// made on display from now on, and returns the serial of the next request
+// to pass to CheckRequest once it is made. Every BeginCheck must be followed
+// by a CheckRequest.
+func BeginCheck(display *Display) uint64 {
+	// No error can come for a request not made yet, so the serial is read
+	// without holding the lock, which the handler takes while Xlib holds
+	// the display.
+	serial := XNextRequest(display)
+	errorHandlers.Lock()
+	defer errorHandlers.Unlock()
+	if errorHandlers.checks == nil {
+		errorHandlers.checks = make(map[*Display]map[uint64]int)
+		errorHandlers.pending = make(map[*Display][]*XError)
+	}
+	if errorHandlers.checks[display] == nil {
+		errorHandlers.checks[display] = make(map[uint64]int)
+	}
+	errorHandlers.checks[display][serial]++
+	return serial
+}
+
+// CheckRequest waits until the X server has processed every request up to
+// now and returns the first error caused by a request sent at or after
+// serial, which comes from BeginCheck. Errors only reach it once
+// XSetErrorHandler or XSetIOErrorHandler has been called, and only while the
+// handlers are in place: see ReinstallErrorHandlers.
+func CheckRequest(display *Display, serial uint64) error {
+	XSync(display, False)
+	return endCheck(display, serial)
+}
+
+// endCheck ends the check started at serial without waiting for the X
+// server, for requests with a reply, whose errors have arrived with it.
+func endCheck(display *Display, serial uint64) error {
+	errorHandlers.Lock()
+	defer errorHandlers.Unlock()
+	var found error
+	pending := errorHandlers.pending[display]
+	for i, err := range pending {
+		if err.Serial >= serial {
+			found = err
+			pending = append(pending[:i:i], pending[i+1:]...)
+			break
+		}
+	}
+
+	checks := errorHandlers.checks[display]
+	if checks[serial]--; checks[serial] <= 0 {
+		delete(checks, serial)
+	}
+	if len(checks) == 0 {
+		delete(errorHandlers.checks, display)
+		delete(errorHandlers.pending, display)
+		return found
+	}
+	// Drop the errors no check is waiting for any more.
+	first := firstCheck(checks)
+	errorHandlers.pending[display] = slices.DeleteFunc(pending, func(err *XError) bool {
+		return err.Serial < first
+	})
+	return found
+}
+
+func firstCheck(checks map[uint64]int) uint64 {
+	first := uint64(math.MaxUint64)
+	for serial := range checks {
+		first = min(first, serial)
+	}
+	return first
+}
+
+func XGetErrorText(display *Display, code int) string {
+	displayC := (*C.Display)(display)
+	var buffer [256]C.char
+	C.XGetErrorText(displayC, C.int(code), &buffer[0], C.int(len(buffer)))
+	return C.GoString(&buffer[0])
+}
+
+func XSync(display *Display, discard int) {
+	displayC := (*C.Display)(display)
+	C.XSync(displayC, C.Bool(discard))
+}
+
+//export goXErrorHandler
+func goXErrorHandler(displayC *C.Display, eventC *C.XErrorEvent) C.int {
+	display := (*Display)(displayC)
+	err := &XError{
+		Display:     display,
+		Serial:      uint64(eventC.serial),
+		ErrorCode:   int(eventC.error_code),
+		RequestCode: int(eventC.request_code),
+		MinorCode:   int(eventC.minor_code),
+		ResourceID:  uint64(eventC.resourceid),
+		Text:        XGetErrorText(display, int(eventC.error_code)),
+	}
+
+	errorHandlers.Lock()
+	if checks := errorHandlers.checks[display]; len(checks) > 0 && err.Serial >= firstCheck(checks) {
+		errorHandlers.pending[display] = append(errorHandlers.pending[display], err)
+	}
+	handler := errorHandlers.handler
+	errorHandlers.Unlock()
+
+	if handler != nil {
+		handler(err)
+	}
+	return 0
+}
+
+//export goXIOErrorHandler
+func goXIOErrorHandler(displayC *C.Display) C.int {
+	errorHandlers.Lock()
+	handler := errorHandlers.ioHandler
+	errorHandlers.Unlock()
+
+	if handler != nil {
+		handler((*Display)(displayC))
+	}
+	return 0
+}
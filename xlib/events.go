@@ -11,11 +11,60 @@ package xlib
 // #include <X11/Xlib.h>
 // #include "xlib.h"
 import "C"
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"unsafe"
+)
+
+const (
+	KeyPress         = int(C.KeyPress)
+	KeyRelease       = int(C.KeyRelease)
+	ButtonPress      = int(C.ButtonPress)
+	ButtonRelease    = int(C.ButtonRelease)
+	MotionNotify     = int(C.MotionNotify)
+	EnterNotify      = int(C.EnterNotify)
+	LeaveNotify      = int(C.LeaveNotify)
+	FocusIn          = int(C.FocusIn)
+	FocusOut         = int(C.FocusOut)
+	KeymapNotify     = int(C.KeymapNotify)
+	Expose           = int(C.Expose)
+	GraphicsExpose   = int(C.GraphicsExpose)
+	NoExpose         = int(C.NoExpose)
+	VisibilityNotify = int(C.VisibilityNotify)
+	CreateNotify     = int(C.CreateNotify)
+	DestroyNotify    = int(C.DestroyNotify)
+	UnmapNotify      = int(C.UnmapNotify)
+	MapNotify        = int(C.MapNotify)
+	MapRequest       = int(C.MapRequest)
+	ReparentNotify   = int(C.ReparentNotify)
+	ConfigureNotify  = int(C.ConfigureNotify)
+	ConfigureRequest = int(C.ConfigureRequest)
+	GravityNotify    = int(C.GravityNotify)
+	ResizeRequest    = int(C.ResizeRequest)
+	CirculateNotify  = int(C.CirculateNotify)
+	CirculateRequest = int(C.CirculateRequest)
+	PropertyNotify   = int(C.PropertyNotify)
+	SelectionClear   = int(C.SelectionClear)
+	SelectionRequest = int(C.SelectionRequest)
+	SelectionNotify  = int(C.SelectionNotify)
+	ColormapNotify   = int(C.ColormapNotify)
+	ClientMessage    = int(C.ClientMessage)
+	MappingNotify    = int(C.MappingNotify)
+	GenericEvent     = int(C.GenericEvent)
+	LASTEvent        = int(C.LASTEvent)
+)
 
 const (
-	Expose     = int(C.Expose)
-	KeyPress   = int(C.KeyPress)
-	KeyRelease = int(C.KeyRelease)
+	// Visibility states.
+	VisibilityUnobscured        = int(C.VisibilityUnobscured)
+	VisibilityPartiallyObscured = int(C.VisibilityPartiallyObscured)
+	VisibilityFullyObscured     = int(C.VisibilityFullyObscured)
+
+	// Property states.
+	PropertyNewValue = int(C.PropertyNewValue)
+	PropertyDelete   = int(C.PropertyDelete)
 )
 
 const (
@@ -105,22 +154,440 @@ type XKeyEvent struct {
 	SameScreen   bool
 }
 
+// XButtonEvent is sent for ButtonPress and ButtonRelease.
+type XButtonEvent struct {
+	tEventType
+	Serial       uint64
+	SendEvent    bool
+	Display      *Display
+	Window       Window
+	Root         Window
+	Subwindow    Window
+	Time         uint64
+	X, Y         int
+	XRoot, YRoot int
+	State        uint
+	Button       uint
+	SameScreen   bool
+}
+
+type XMotionEvent struct {
+	tEventType
+	Serial       uint64
+	SendEvent    bool
+	Display      *Display
+	Window       Window
+	Root         Window
+	Subwindow    Window
+	Time         uint64
+	X, Y         int
+	XRoot, YRoot int
+	State        uint
+	IsHint       bool
+	SameScreen   bool
+}
+
+type XExposeEvent struct {
+	tEventType
+	Serial        uint64
+	SendEvent     bool
+	Display       *Display
+	Window        Window
+	X, Y          int
+	Width, Height int
+	Count         int
+}
+
+type XVisibilityEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Window    Window
+	State     int
+}
+
+type XDestroyWindowEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Event     Window
+	Window    Window
+}
+
+type XUnmapEvent struct {
+	tEventType
+	Serial        uint64
+	SendEvent     bool
+	Display       *Display
+	Event         Window
+	Window        Window
+	FromConfigure bool
+}
+
+type XMapEvent struct {
+	tEventType
+	Serial           uint64
+	SendEvent        bool
+	Display          *Display
+	Event            Window
+	Window           Window
+	OverrideRedirect bool
+}
+
+type XConfigureEvent struct {
+	tEventType
+	Serial           uint64
+	SendEvent        bool
+	Display          *Display
+	Event            Window
+	Window           Window
+	X, Y             int
+	Width, Height    int
+	BorderWidth      int
+	Above            Window
+	OverrideRedirect bool
+}
+
+type XPropertyEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Window    Window
+	Atom      Atom
+	Time      uint64
+	State     int
+}
+
+type XSelectionClearEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Window    Window
+	Selection Atom
+	Time      uint64
+}
+
+// XClientMessageEvent carries 20 bytes of data, read as Bytes when Format is
+// 8 and as Longs when it is 32.
+type XClientMessageEvent struct {
+	tEventType
+	Serial      uint64
+	SendEvent   bool
+	Display     *Display
+	Window      Window
+	MessageType Atom
+	Format      int
+	Bytes       [20]byte
+	Longs       [5]int64
+}
+
+// XGenericEvent is a GenericEvent from an extension such as XInput 2. Its
+// payload is not fetched.
+type XGenericEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Extension int
+	EvType    int
+}
+
+// XAnyEvent is returned for every event that has no specific type, including
+// the events of extensions without a registered decoder.
+type XAnyEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Window    Window
+}
+
+// eventDecoders decode the events of extensions, which have type codes from
+// LASTEvent on that are only known once the extension has been queried.
+// A decoder returns nil for events it does not know.
+var eventDecoders struct {
+	sync.RWMutex
+	list []func(xeventC *C.XEvent, typeCode int) XEvent
+}
+
+func registerEventDecoder(decode func(xeventC *C.XEvent, typeCode int) XEvent) {
+	eventDecoders.Lock()
+	defer eventDecoders.Unlock()
+	eventDecoders.list = append(eventDecoders.list, decode)
+}
+
 func XNextEvent(display *Display) XEvent {
 	displayC := (*C.Display)(display)
 	var xeventC C.XEvent
-	var xeventTypeC C.int
 	C.XNextEvent(displayC, &xeventC)
-	C.xlib_xevent_type(&xeventC, &xeventTypeC)
+	return decodeEvent(&xeventC)
+}
+
+func decodeEvent(xeventC *C.XEvent) XEvent {
+	var xeventTypeC C.int
+	C.xlib_xevent_type(xeventC, &xeventTypeC)
+	typeCode := int(xeventTypeC)
+
+	// The members of the XEvent union are read by casting it to the struct
+	// of the event type, which cgo cannot do on its own.
+	p := unsafe.Pointer(xeventC)
+	switch typeCode {
+	case KeyPress, KeyRelease:
+		return newXKeyEvent(xeventC, xeventTypeC)
+	case ButtonPress, ButtonRelease:
+		e := (*C.XButtonEvent)(p)
+		return &XButtonEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			Root:       Window(e.root),
+			Subwindow:  Window(e.subwindow),
+			Time:       uint64(e.time),
+			X:          int(e.x),
+			Y:          int(e.y),
+			XRoot:      int(e.x_root),
+			YRoot:      int(e.y_root),
+			State:      uint(e.state),
+			Button:     uint(e.button),
+			SameScreen: e.same_screen != 0,
+		}
+	case MotionNotify:
+		e := (*C.XMotionEvent)(p)
+		return &XMotionEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			Root:       Window(e.root),
+			Subwindow:  Window(e.subwindow),
+			Time:       uint64(e.time),
+			X:          int(e.x),
+			Y:          int(e.y),
+			XRoot:      int(e.x_root),
+			YRoot:      int(e.y_root),
+			State:      uint(e.state),
+			IsHint:     e.is_hint != 0,
+			SameScreen: e.same_screen != 0,
+		}
+	case Expose:
+		e := (*C.XExposeEvent)(p)
+		return &XExposeEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			X:          int(e.x),
+			Y:          int(e.y),
+			Width:      int(e.width),
+			Height:     int(e.height),
+			Count:      int(e.count),
+		}
+	case VisibilityNotify:
+		e := (*C.XVisibilityEvent)(p)
+		return &XVisibilityEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			State:      int(e.state),
+		}
+	case DestroyNotify:
+		e := (*C.XDestroyWindowEvent)(p)
+		return &XDestroyWindowEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Event:      Window(e.event),
+			Window:     Window(e.window),
+		}
+	case UnmapNotify:
+		e := (*C.XUnmapEvent)(p)
+		return &XUnmapEvent{
+			tEventType:    tEventType{typeCode},
+			Serial:        uint64(e.serial),
+			SendEvent:     e.send_event != 0,
+			Display:       (*Display)(e.display),
+			Event:         Window(e.event),
+			Window:        Window(e.window),
+			FromConfigure: e.from_configure != 0,
+		}
+	case MapNotify:
+		e := (*C.XMapEvent)(p)
+		return &XMapEvent{
+			tEventType:       tEventType{typeCode},
+			Serial:           uint64(e.serial),
+			SendEvent:        e.send_event != 0,
+			Display:          (*Display)(e.display),
+			Event:            Window(e.event),
+			Window:           Window(e.window),
+			OverrideRedirect: e.override_redirect != 0,
+		}
+	case ConfigureNotify:
+		e := (*C.XConfigureEvent)(p)
+		return &XConfigureEvent{
+			tEventType:       tEventType{typeCode},
+			Serial:           uint64(e.serial),
+			SendEvent:        e.send_event != 0,
+			Display:          (*Display)(e.display),
+			Event:            Window(e.event),
+			Window:           Window(e.window),
+			X:                int(e.x),
+			Y:                int(e.y),
+			Width:            int(e.width),
+			Height:           int(e.height),
+			BorderWidth:      int(e.border_width),
+			Above:            Window(e.above),
+			OverrideRedirect: e.override_redirect != 0,
+		}
+	case PropertyNotify:
+		e := (*C.XPropertyEvent)(p)
+		return &XPropertyEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			Atom:       Atom(e.atom),
+			Time:       uint64(e.time),
+			State:      int(e.state),
+		}
+	case SelectionClear:
+		e := (*C.XSelectionClearEvent)(p)
+		return &XSelectionClearEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Window:     Window(e.window),
+			Selection:  Atom(e.selection),
+			Time:       uint64(e.time),
+		}
+	case ClientMessage:
+		e := (*C.XClientMessageEvent)(p)
+		event := &XClientMessageEvent{
+			tEventType:  tEventType{typeCode},
+			Serial:      uint64(e.serial),
+			SendEvent:   e.send_event != 0,
+			Display:     (*Display)(e.display),
+			Window:      Window(e.window),
+			MessageType: Atom(e.message_type),
+			Format:      int(e.format),
+		}
+		data := unsafe.Pointer(&e.data)
+		copy(event.Bytes[:], unsafe.Slice((*byte)(data), len(event.Bytes)))
+		for i, l := range unsafe.Slice((*C.long)(data), len(event.Longs)) {
+			event.Longs[i] = int64(l)
+		}
+		return event
+	case GenericEvent:
+		e := (*C.XGenericEvent)(p)
+		return &XGenericEvent{
+			tEventType: tEventType{typeCode},
+			Serial:     uint64(e.serial),
+			SendEvent:  e.send_event != 0,
+			Display:    (*Display)(e.display),
+			Extension:  int(e.extension),
+			EvType:     int(e.evtype),
+		}
+	}
+
+	if typeCode >= LASTEvent {
+		eventDecoders.RLock()
+		decoders := eventDecoders.list
+		eventDecoders.RUnlock()
+		for _, decode := range decoders {
+			if event := decode(xeventC, typeCode); event != nil {
+				return event
+			}
+		}
+	}
+
+	e := (*C.XAnyEvent)(p)
+	return &XAnyEvent{
+		tEventType: tEventType{typeCode},
+		Serial:     uint64(e.serial),
+		SendEvent:  e.send_event != 0,
+		Display:    (*Display)(e.display),
+		Window:     Window(e.window),
+	}
+}
 
-	switch xeventTypeC {
-	case C.KeyPress:
-		return newXKeyEvent(&xeventC, xeventTypeC)
-	case C.KeyRelease:
-		return newXKeyEvent(&xeventC, xeventTypeC)
+// XSendEvent sends event to window, as the same function in Xlib does. Only
+// button and client message events can be sent.
+func XSendEvent(display *Display, window Window, propagate int, eventMask int64, event XEvent) error {
+	displayC := (*C.Display)(display)
+	var xeventC C.XEvent
+	if err := encodeEvent(&xeventC, event); err != nil {
+		return err
+	}
+	serial := BeginCheck(display)
+	converted := C.XSendEvent(displayC, C.Window(window), C.Bool(propagate), C.long(eventMask), &xeventC) != 0
+	err := CheckRequest(display, serial)
+	if !converted {
+		return errors.New("xlib: XSendEvent could not convert the event")
+	}
+	return err
+}
+
+func encodeEvent(xeventC *C.XEvent, event XEvent) error {
+	p := unsafe.Pointer(xeventC)
+	switch event := event.(type) {
+	case *XButtonEvent:
+		e := (*C.XButtonEvent)(p)
+		e._type = C.int(event.typeCode)
+		e.serial = C.ulong(event.Serial)
+		e.send_event = boolC(event.SendEvent)
+		e.display = (*C.Display)(event.Display)
+		e.window = C.Window(event.Window)
+		e.root = C.Window(event.Root)
+		e.subwindow = C.Window(event.Subwindow)
+		e.time = C.Time(event.Time)
+		e.x, e.y = C.int(event.X), C.int(event.Y)
+		e.x_root, e.y_root = C.int(event.XRoot), C.int(event.YRoot)
+		e.state = C.uint(event.State)
+		e.button = C.uint(event.Button)
+		e.same_screen = boolC(event.SameScreen)
+	case *XClientMessageEvent:
+		e := (*C.XClientMessageEvent)(p)
+		e._type = C.ClientMessage
+		e.serial = C.ulong(event.Serial)
+		e.send_event = boolC(event.SendEvent)
+		e.display = (*C.Display)(event.Display)
+		e.window = C.Window(event.Window)
+		e.message_type = C.Atom(event.MessageType)
+		e.format = C.int(event.Format)
+		data := unsafe.Pointer(&e.data)
+		if event.Format == 32 {
+			longs := unsafe.Slice((*C.long)(data), len(event.Longs))
+			for i, l := range event.Longs {
+				longs[i] = C.long(l)
+			}
+		} else {
+			copy(unsafe.Slice((*byte)(data), len(event.Bytes)), event.Bytes[:])
+		}
+	default:
+		return fmt.Errorf("xlib: cannot send events of type %d", event.Type())
 	}
 	return nil
 }
 
+func boolC(b bool) C.Bool {
+	if b {
+		return C.True
+	}
+	return C.False
+}
+
 func newXKeyEvent(xeventC *C.XEvent, xeventTypeC C.int) *XKeyEvent {
 	xKeyEvent := new(XKeyEvent)
 	var serialC C.ulong
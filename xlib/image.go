@@ -0,0 +1,145 @@
+package xlib
+
+// #include <stdlib.h>
+// #include <X11/Xlib.h>
+// #include <X11/Xutil.h>
+// #include "xlib.h"
+import "C"
+import (
+	"errors"
+	"image"
+	"math/bits"
+	"unsafe"
+)
+
+type Pixmap C.Pixmap
+type XImage C.XImage
+
+const (
+	ZPixmap = C.ZPixmap
+
+	DestroyAll      = C.DestroyAll
+	RetainPermanent = C.RetainPermanent
+	RetainTemporary = C.RetainTemporary
+)
+
+func XDefaultVisual(display *Display, screenNumber int) *Visual {
+	displayC := (*C.Display)(display)
+	return (*Visual)(C.XDefaultVisual(displayC, C.int(screenNumber)))
+}
+
+func XCreatePixmap(display *Display, drawable Window, width, height uint, depth int) Pixmap {
+	displayC := (*C.Display)(display)
+	drawableC := (C.Drawable)(drawable)
+	return Pixmap(C.XCreatePixmap(displayC, drawableC, C.uint(width), C.uint(height), C.uint(depth)))
+}
+
+func XFreePixmap(display *Display, pixmap Pixmap) {
+	displayC := (*C.Display)(display)
+	C.XFreePixmap(displayC, C.Pixmap(pixmap))
+}
+
+// XCreateBitmapFromData creates a pixmap of depth 1 from data in the XBM
+// layout: rows padded to whole bytes, with the leftmost pixel of each byte in
+// its least significant bit.
+func XCreateBitmapFromData(display *Display, drawable Window, data []byte, width, height uint) Pixmap {
+	displayC := (*C.Display)(display)
+	drawableC := (C.Drawable)(drawable)
+	dataC := C.CBytes(data)
+	defer C.free(dataC)
+	return Pixmap(C.XCreateBitmapFromData(displayC, drawableC, (*C.char)(dataC), C.uint(width), C.uint(height)))
+}
+
+// XCreateImage creates a ZPixmap image with zeroed data of its own, which
+// XDestroyImage frees.
+func XCreateImage(display *Display, visual *Visual, depth int, width, height uint) *XImage {
+	displayC := (*C.Display)(display)
+	visualC := (*C.Visual)(visual)
+	imageC := C.XCreateImage(displayC, visualC, C.uint(depth), C.ZPixmap, 0, nil, C.uint(width), C.uint(height), 32, 0)
+	if imageC == nil {
+		return nil
+	}
+	imageC.data = (*C.char)(C.calloc(C.size_t(imageC.height), C.size_t(imageC.bytes_per_line)))
+	return (*XImage)(imageC)
+}
+
+func XDestroyImage(image *XImage) {
+	C.xlib_destroy_image((*C.XImage)(image))
+}
+
+func XPutImage(display *Display, drawable Window, gc C.GC, image *XImage, srcX, srcY, destX, destY int, width, height uint) {
+	displayC := (*C.Display)(display)
+	drawableC := (C.Drawable)(drawable)
+	C.XPutImage(displayC, drawableC, gc, (*C.XImage)(image), C.int(srcX), C.int(srcY), C.int(destX), C.int(destY), C.uint(width), C.uint(height))
+}
+
+// CreateImage converts img into an image for a TrueColor visual with 32 bits
+// per pixel, which is what servers use for depths 24 and 32.
+func CreateImage(display *Display, visual *Visual, depth int, img image.Image) (*XImage, error) {
+	bounds := img.Bounds()
+	ximage := XCreateImage(display, visual, depth, uint(bounds.Dx()), uint(bounds.Dy()))
+	if ximage == nil {
+		return nil, errors.New("xlib: XCreateImage failed")
+	}
+	imageC := (*C.XImage)(ximage)
+	if imageC.bits_per_pixel != 32 {
+		XDestroyImage(ximage)
+		return nil, errors.New("xlib: only images with 32 bits per pixel are supported")
+	}
+
+	// Pixels are written least significant byte first; Xlib swaps them if
+	// the server wants the other order.
+	imageC.byte_order = C.LSBFirst
+	redMask, greenMask, blueMask := uint32(imageC.red_mask), uint32(imageC.green_mask), uint32(imageC.blue_mask)
+	channel := func(value, mask uint32) uint32 {
+		// Scale the 16 bit value to the width of the mask and move it in.
+		width := bits.OnesCount32(mask)
+		return value >> (16 - width) << bits.TrailingZeros32(mask) & mask
+	}
+
+	// Skip the color conversion for what PNG decoding usually gives.
+	rgba, isRGBA := img.(*image.RGBA)
+	stride := int(imageC.bytes_per_line)
+	data := unsafe.Slice((*byte)(unsafe.Pointer(imageC.data)), stride*bounds.Dy())
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := data[(y-bounds.Min.Y)*stride:]
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			var r, g, b uint32
+			if isRGBA {
+				p := rgba.Pix[rgba.PixOffset(x, y):]
+				r, g, b = uint32(p[0])*0x101, uint32(p[1])*0x101, uint32(p[2])*0x101
+			} else {
+				r, g, b, _ = img.At(x, y).RGBA()
+			}
+			pixel := channel(r, redMask) | channel(g, greenMask) | channel(b, blueMask)
+			i := (x - bounds.Min.X) * 4
+			row[i], row[i+1], row[i+2], row[i+3] = byte(pixel), byte(pixel>>8), byte(pixel>>16), byte(pixel>>24)
+		}
+	}
+	return ximage, nil
+}
+
+func XSetWindowBackgroundPixmap(display *Display, window Window, pixmap Pixmap) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XSetWindowBackgroundPixmap(displayC, windowC, C.Pixmap(pixmap))
+}
+
+func XClearWindow(display *Display, window Window) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XClearWindow(displayC, windowC)
+}
+
+// XKillClient frees all resources of the client that created resource, or
+// the resources kept by clients that closed their connection with
+// RetainTemporary if resource is AllTemporary.
+func XKillClient(display *Display, resource uint64) {
+	displayC := (*C.Display)(display)
+	C.XKillClient(displayC, C.XID(resource))
+}
+
+func XSetCloseDownMode(display *Display, mode int) {
+	displayC := (*C.Display)(display)
+	C.XSetCloseDownMode(displayC, C.int(mode))
+}
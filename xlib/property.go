@@ -0,0 +1,212 @@
+package xlib
+
+// #include <X11/Xlib.h>
+// #include <X11/Xatom.h>
+import "C"
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// ErrNoProperty is returned when a window does not have the property asked
+// for.
+var ErrNoProperty = errors.New("xlib: no such property")
+
+// Property is the value of a window property. Data holds the raw bytes of
+// format 8 properties and Values the items of format 16 and 32 ones, which
+// Xlib hands out as C shorts and longs whatever their size on the wire.
+type Property struct {
+	Type   Atom
+	Format int
+	Data   []byte
+	Values []uint64
+}
+
+// propertyChunk is how many 32 bit units XGetWindowProperty is asked for at
+// first; longer values are fetched again in full.
+const propertyChunk = 1 << 16
+
+// XGetWindowProperty reads a property of the window. It returns ErrNoProperty
+// if the window has no such property, and an error mentioning both types if
+// reqType is not AnyPropertyType and does not match the type of the property.
+func XGetWindowProperty(display *Display, window Window, property Atom, longOffset, longLength int64, delete int, reqType Atom) (*Property, uint64, error) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	var actualTypeC C.Atom
+	var actualFormatC C.int
+	var nItemsC, bytesAfterC C.ulong
+	var dataC *C.uchar
+
+	serial := BeginCheck(display)
+	status := C.XGetWindowProperty(displayC, windowC, C.Atom(property), C.long(longOffset), C.long(longLength),
+		C.Bool(delete), C.Atom(reqType), &actualTypeC, &actualFormatC, &nItemsC, &bytesAfterC, &dataC)
+	// The reply brought any error with it.
+	err := endCheck(display, serial)
+	if status != C.Success {
+		if err != nil {
+			return nil, 0, err
+		}
+		return nil, 0, fmt.Errorf("xlib: XGetWindowProperty failed with status %d", int(status))
+	}
+	if dataC != nil {
+		defer C.XFree(unsafe.Pointer(dataC))
+	}
+
+	if Atom(actualTypeC) == None {
+		return nil, 0, ErrNoProperty
+	}
+	p := &Property{Type: Atom(actualTypeC), Format: int(actualFormatC)}
+	if reqType != Atom(AnyPropertyType) && p.Type != reqType {
+		return p, uint64(bytesAfterC), fmt.Errorf("xlib: property %s has type %s, want %s",
+			XGetAtomName(display, property), XGetAtomName(display, p.Type), XGetAtomName(display, reqType))
+	}
+
+	n := int(nItemsC)
+	switch p.Format {
+	case 8:
+		p.Data = C.GoBytes(unsafe.Pointer(dataC), C.int(n))
+	case 16:
+		for _, v := range unsafe.Slice((*C.ushort)(unsafe.Pointer(dataC)), n) {
+			p.Values = append(p.Values, uint64(v))
+		}
+	case 32:
+		for _, v := range unsafe.Slice((*C.ulong)(unsafe.Pointer(dataC)), n) {
+			p.Values = append(p.Values, uint64(v)&0xffffffff)
+		}
+	}
+	return p, uint64(bytesAfterC), nil
+}
+
+// getProperty reads the whole value of a property of the given type.
+func getProperty(display *Display, window Window, property, reqType Atom) (*Property, error) {
+	length := int64(propertyChunk)
+	for {
+		p, bytesAfter, err := XGetWindowProperty(display, window, property, 0, length, False, reqType)
+		if err != nil {
+			return nil, err
+		}
+		if bytesAfter == 0 {
+			return p, nil
+		}
+		length += int64(bytesAfter+3) / 4
+	}
+}
+
+// GetLongList reads a format 32 property of the given type, such as a list of
+// pixmaps.
+func GetLongList(display *Display, window Window, property, reqType Atom) ([]uint64, error) {
+	p, err := getProperty(display, window, property, reqType)
+	if err != nil {
+		return nil, err
+	}
+	if p.Format != 32 {
+		return nil, fmt.Errorf("xlib: property %s has format %d, want 32", XGetAtomName(display, property), p.Format)
+	}
+	return p.Values, nil
+}
+
+func GetAtomList(display *Display, window Window, property Atom) ([]Atom, error) {
+	values, err := GetLongList(display, window, property, XA_ATOM)
+	if err != nil {
+		return nil, err
+	}
+	atoms := make([]Atom, len(values))
+	for i, v := range values {
+		atoms[i] = Atom(v)
+	}
+	return atoms, nil
+}
+
+func GetCardinalList(display *Display, window Window, property Atom) ([]uint32, error) {
+	values, err := GetLongList(display, window, property, XA_CARDINAL)
+	if err != nil {
+		return nil, err
+	}
+	cardinals := make([]uint32, len(values))
+	for i, v := range values {
+		cardinals[i] = uint32(v)
+	}
+	return cardinals, nil
+}
+
+func GetWindowList(display *Display, window Window, property Atom) ([]Window, error) {
+	values, err := GetLongList(display, window, property, XA_WINDOW)
+	if err != nil {
+		return nil, err
+	}
+	windows := make([]Window, len(values))
+	for i, v := range values {
+		windows[i] = Window(v)
+	}
+	return windows, nil
+}
+
+// GetUTF8String reads a property of type UTF8_STRING.
+func GetUTF8String(display *Display, window Window, property Atom) (string, error) {
+	p, err := getProperty(display, window, property, XInternAtom(display, "UTF8_STRING", False))
+	if err != nil {
+		return "", err
+	}
+	return string(p.Data), nil
+}
+
+// GetString reads a property of type STRING, converting it from Latin-1.
+func GetString(display *Display, window Window, property Atom) (string, error) {
+	p, err := getProperty(display, window, property, XA_STRING)
+	if err != nil {
+		return "", err
+	}
+	runes := make([]rune, len(p.Data))
+	for i, b := range p.Data {
+		runes[i] = rune(b)
+	}
+	return string(runes), nil
+}
+
+func XListProperties(display *Display, window Window) []Atom {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	var nC C.int
+	atomsC := C.XListProperties(displayC, windowC, &nC)
+	if atomsC == nil {
+		return nil
+	}
+	defer C.XFree(unsafe.Pointer(atomsC))
+	return copySlice[Atom](unsafe.Pointer(atomsC), int(nC))
+}
+
+func XDeleteProperty(display *Display, window Window, property Atom) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XDeleteProperty(displayC, windowC, C.Atom(property))
+}
+
+func XGetAtomName(display *Display, atom Atom) string {
+	displayC := (*C.Display)(display)
+	nameC := C.XGetAtomName(displayC, C.Atom(atom))
+	if nameC == nil {
+		return ""
+	}
+	defer C.XFree(unsafe.Pointer(nameC))
+	return C.GoString(nameC)
+}
+
+// SetLongList replaces or extends a format 32 property with the given values,
+// passing them to Xlib as the C longs it expects. Errors go to the error
+// handler; use BeginCheck and CheckRequest to wait for them.
+func SetLongList(display *Display, window Window, property, _type Atom, mode int, values []uint64) {
+	longs := make([]C.long, max(len(values), 1))
+	for i, v := range values {
+		longs[i] = C.long(v)
+	}
+	XChangeProperty(display, window, property, _type, 32, mode, unsafe.Pointer(&longs[0]), len(values))
+}
+
+// SetBytes replaces or extends a format 8 property with the given data, like
+// SetLongList.
+func SetBytes(display *Display, window Window, property, _type Atom, mode int, data []byte) {
+	buf := make([]byte, max(len(data), 1))
+	copy(buf, data)
+	XChangeProperty(display, window, property, _type, 8, mode, unsafe.Pointer(&buf[0]), len(data))
+}
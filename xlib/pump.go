@@ -0,0 +1,81 @@
+package xlib
+
+// #include <poll.h>
+// #include <X11/Xlib.h>
+import "C"
+import (
+	"context"
+	"os"
+)
+
+func XInitThreads() int {
+	return int(C.XInitThreads())
+}
+
+func XLockDisplay(display *Display) {
+	C.XLockDisplay((*C.Display)(display))
+}
+
+func XUnlockDisplay(display *Display) {
+	C.XUnlockDisplay((*C.Display)(display))
+}
+
+func XPending(display *Display) int {
+	displayC := (*C.Display)(display)
+	return int(C.XPending(displayC))
+}
+
+func XConnectionNumber(display *Display) int {
+	displayC := (*C.Display)(display)
+	return int(C.XConnectionNumber(displayC))
+}
+
+// pumpInterval bounds, in milliseconds, how long events read from the
+// connection by another thread, for example while it waits in XSync, can sit
+// in the queue before the pump notices them.
+const pumpInterval = 500
+
+// Events starts a goroutine that reads every event of the display and sends
+// it on the returned channel, which is closed once ctx is cancelled. The
+// goroutine waits on the connection file descriptor instead of blocking in
+// XNextEvent, so it never holds the display lock while idle. XInitThreads
+// must have been called before the display was opened, since other threads
+// keep using it. The goroutine uses the display until the channel is closed,
+// so cancel ctx and drain the channel before closing the display.
+func Events(ctx context.Context, display *Display) <-chan XEvent {
+	events := make(chan XEvent, 64)
+	go func() {
+		defer close(events)
+
+		wakeR, wakeW, err := os.Pipe()
+		if err != nil {
+			return
+		}
+		defer wakeR.Close()
+		stop := context.AfterFunc(ctx, func() { wakeW.Close() })
+		defer func() {
+			if stop() {
+				wakeW.Close()
+			}
+		}()
+
+		fds := [2]C.struct_pollfd{
+			{fd: C.int(XConnectionNumber(display)), events: C.POLLIN},
+			{fd: C.int(wakeR.Fd()), events: C.POLLIN},
+		}
+		for {
+			for XPending(display) > 0 {
+				select {
+				case events <- XNextEvent(display):
+				case <-ctx.Done():
+					return
+				}
+			}
+			C.poll(&fds[0], C.nfds_t(len(fds)), pumpInterval)
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return events
+}
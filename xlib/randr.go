@@ -0,0 +1,369 @@
+package xlib
+
+// #cgo LDFLAGS: -lXrandr
+// #include <X11/Xlib.h>
+// #include <X11/extensions/Xrandr.h>
+import "C"
+import (
+	"sync"
+	"unsafe"
+)
+
+type RRCrtc C.RRCrtc
+type RROutput C.RROutput
+type RRMode C.RRMode
+type Rotation uint16
+type XRRScreenResources C.XRRScreenResources
+
+const (
+	RR_Rotate_0   = Rotation(C.RR_Rotate_0)
+	RR_Rotate_90  = Rotation(C.RR_Rotate_90)
+	RR_Rotate_180 = Rotation(C.RR_Rotate_180)
+	RR_Rotate_270 = Rotation(C.RR_Rotate_270)
+	RR_Reflect_X  = Rotation(C.RR_Reflect_X)
+	RR_Reflect_Y  = Rotation(C.RR_Reflect_Y)
+)
+
+const (
+	RRScreenChangeNotifyMask   = int(C.RRScreenChangeNotifyMask)
+	RRCrtcChangeNotifyMask     = int(C.RRCrtcChangeNotifyMask)
+	RROutputChangeNotifyMask   = int(C.RROutputChangeNotifyMask)
+	RROutputPropertyNotifyMask = int(C.RROutputPropertyNotifyMask)
+
+	RRScreenChangeNotify = int(C.RRScreenChangeNotify)
+	RRNotify             = int(C.RRNotify)
+
+	RRNotify_CrtcChange   = int(C.RRNotify_CrtcChange)
+	RRNotify_OutputChange = int(C.RRNotify_OutputChange)
+
+	RR_Connected         = int(C.RR_Connected)
+	RR_Disconnected      = int(C.RR_Disconnected)
+	RR_UnknownConnection = int(C.RR_UnknownConnection)
+
+	RR_Interlace  = uint64(C.RR_Interlace)
+	RR_DoubleScan = uint64(C.RR_DoubleScan)
+)
+
+type ModeInfo struct {
+	ID            RRMode
+	Width, Height uint
+	DotClock      uint64
+	HTotal        uint
+	VTotal        uint
+	Name          string
+	Flags         uint64
+}
+
+// RefreshRate returns the vertical refresh rate of the mode in Hz, or 0 if
+// it cannot be computed.
+func (mode *ModeInfo) RefreshRate() float64 {
+	vTotal := float64(mode.VTotal)
+	if mode.Flags&RR_DoubleScan != 0 {
+		vTotal *= 2
+	}
+	if mode.Flags&RR_Interlace != 0 {
+		vTotal /= 2
+	}
+	if mode.HTotal == 0 || vTotal == 0 {
+		return 0
+	}
+	return float64(mode.DotClock) / (float64(mode.HTotal) * vTotal)
+}
+
+type OutputInfo struct {
+	Timestamp         uint64
+	Crtc              RRCrtc
+	Name              string
+	MMWidth, MMHeight uint
+	Connection        int
+	Crtcs             []RRCrtc
+	Clones            []RROutput
+	Modes             []RRMode
+	Preferred         int
+}
+
+type MonitorInfo struct {
+	Name              Atom
+	Primary           bool
+	Automatic         bool
+	X, Y              int
+	Width, Height     uint
+	MMWidth, MMHeight uint
+	Outputs           []RROutput
+}
+
+type XRRScreenChangeNotifyEvent struct {
+	tEventType
+	Serial            uint64
+	SendEvent         bool
+	Display           *Display
+	Window            Window
+	Root              Window
+	Timestamp         uint64
+	ConfigTimestamp   uint64
+	Rotation          Rotation
+	Width, Height     int
+	MMWidth, MMHeight int
+}
+
+type XRRCrtcChangeNotifyEvent struct {
+	tEventType
+	Serial        uint64
+	SendEvent     bool
+	Display       *Display
+	Window        Window
+	Crtc          RRCrtc
+	Mode          RRMode
+	Rotation      Rotation
+	X, Y          int
+	Width, Height uint
+}
+
+type XRROutputChangeNotifyEvent struct {
+	tEventType
+	Serial     uint64
+	SendEvent  bool
+	Display    *Display
+	Window     Window
+	Output     RROutput
+	Crtc       RRCrtc
+	Mode       RRMode
+	Rotation   Rotation
+	Connection int
+}
+
+var randrDecoder sync.Once
+
+// XRRQueryExtension reports whether the server supports RandR. The first
+// successful call also makes XNextEvent decode RandR events.
+func XRRQueryExtension(display *Display) (eventBase, errorBase int, ok bool) {
+	displayC := (*C.Display)(display)
+	var eventBaseC, errorBaseC C.int
+	if C.XRRQueryExtension(displayC, &eventBaseC, &errorBaseC) == 0 {
+		return 0, 0, false
+	}
+	eventBase, errorBase = int(eventBaseC), int(errorBaseC)
+	randrDecoder.Do(func() {
+		registerEventDecoder(func(xeventC *C.XEvent, typeCode int) XEvent {
+			return decodeRandREvent(xeventC, typeCode-eventBase)
+		})
+	})
+	return eventBase, errorBase, true
+}
+
+func XRRQueryVersion(display *Display) (major, minor int, ok bool) {
+	displayC := (*C.Display)(display)
+	var majorC, minorC C.int
+	if C.XRRQueryVersion(displayC, &majorC, &minorC) == 0 {
+		return 0, 0, false
+	}
+	return int(majorC), int(minorC), true
+}
+
+func XRRSelectInput(display *Display, window Window, mask int) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XRRSelectInput(displayC, windowC, C.int(mask))
+}
+
+type CrtcInfo struct {
+	Timestamp     uint64
+	X, Y          int
+	Width, Height uint
+	Mode          RRMode
+	Rotation      Rotation
+	Outputs       []RROutput
+	Rotations     Rotation
+	Possible      []RROutput
+}
+
+func XRRGetScreenResourcesCurrent(display *Display, window Window) *XRRScreenResources {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	resources := C.XRRGetScreenResourcesCurrent(displayC, windowC)
+	return (*XRRScreenResources)(resources)
+}
+
+func XRRFreeScreenResources(resources *XRRScreenResources) {
+	C.XRRFreeScreenResources((*C.XRRScreenResources)(resources))
+}
+
+func (resources *XRRScreenResources) Crtcs() []RRCrtc {
+	return copySlice[RRCrtc](unsafe.Pointer(resources.crtcs), int(resources.ncrtc))
+}
+
+func (resources *XRRScreenResources) Outputs() []RROutput {
+	return copySlice[RROutput](unsafe.Pointer(resources.outputs), int(resources.noutput))
+}
+
+func (resources *XRRScreenResources) Modes() []ModeInfo {
+	modesC := unsafe.Slice(resources.modes, int(resources.nmode))
+	modes := make([]ModeInfo, len(modesC))
+	for i, mode := range modesC {
+		modes[i] = ModeInfo{
+			ID:       RRMode(mode.id),
+			Width:    uint(mode.width),
+			Height:   uint(mode.height),
+			DotClock: uint64(mode.dotClock),
+			HTotal:   uint(mode.hTotal),
+			VTotal:   uint(mode.vTotal),
+			Name:     C.GoStringN(mode.name, C.int(mode.nameLength)),
+			Flags:    uint64(mode.modeFlags),
+		}
+	}
+	return modes
+}
+
+// XRRGetOutputInfo returns a copy of the output information, or nil if the
+// request failed.
+func XRRGetOutputInfo(display *Display, resources *XRRScreenResources, output RROutput) *OutputInfo {
+	displayC := (*C.Display)(display)
+	resourcesC := (*C.XRRScreenResources)(resources)
+	infoC := C.XRRGetOutputInfo(displayC, resourcesC, C.RROutput(output))
+	if infoC == nil {
+		return nil
+	}
+	defer C.XRRFreeOutputInfo(infoC)
+	return &OutputInfo{
+		Timestamp:  uint64(infoC.timestamp),
+		Crtc:       RRCrtc(infoC.crtc),
+		Name:       C.GoStringN(infoC.name, infoC.nameLen),
+		MMWidth:    uint(infoC.mm_width),
+		MMHeight:   uint(infoC.mm_height),
+		Connection: int(infoC.connection),
+		Crtcs:      copySlice[RRCrtc](unsafe.Pointer(infoC.crtcs), int(infoC.ncrtc)),
+		Clones:     copySlice[RROutput](unsafe.Pointer(infoC.clones), int(infoC.nclone)),
+		Modes:      copySlice[RRMode](unsafe.Pointer(infoC.modes), int(infoC.nmode)),
+		Preferred:  int(infoC.npreferred),
+	}
+}
+
+func XRRGetOutputPrimary(display *Display, window Window) RROutput {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	return RROutput(C.XRRGetOutputPrimary(displayC, windowC))
+}
+
+// XRRGetMonitors returns the monitors of the screen, which needs RandR 1.5.
+// If getActive is True only monitors with an enabled output are listed.
+func XRRGetMonitors(display *Display, window Window, getActive int) []MonitorInfo {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	var nC C.int
+	monitorsC := C.XRRGetMonitors(displayC, windowC, C.Bool(getActive), &nC)
+	if monitorsC == nil {
+		return nil
+	}
+	defer C.XRRFreeMonitors(monitorsC)
+	monitors := make([]MonitorInfo, int(nC))
+	for i, m := range unsafe.Slice(monitorsC, int(nC)) {
+		monitors[i] = MonitorInfo{
+			Name:      Atom(m.name),
+			Primary:   m.primary != 0,
+			Automatic: m.automatic != 0,
+			X:         int(m.x),
+			Y:         int(m.y),
+			Width:     uint(m.width),
+			Height:    uint(m.height),
+			MMWidth:   uint(m.mwidth),
+			MMHeight:  uint(m.mheight),
+			Outputs:   copySlice[RROutput](unsafe.Pointer(m.outputs), int(m.noutput)),
+		}
+	}
+	return monitors
+}
+
+// XRRGetCrtcInfo returns a copy of the CRTC information, or nil if the
+// request failed.
+func XRRGetCrtcInfo(display *Display, resources *XRRScreenResources, crtc RRCrtc) *CrtcInfo {
+	displayC := (*C.Display)(display)
+	resourcesC := (*C.XRRScreenResources)(resources)
+	infoC := C.XRRGetCrtcInfo(displayC, resourcesC, C.RRCrtc(crtc))
+	if infoC == nil {
+		return nil
+	}
+	defer C.XRRFreeCrtcInfo(infoC)
+	return &CrtcInfo{
+		Timestamp: uint64(infoC.timestamp),
+		X:         int(infoC.x),
+		Y:         int(infoC.y),
+		Width:     uint(infoC.width),
+		Height:    uint(infoC.height),
+		Mode:      RRMode(infoC.mode),
+		Rotation:  Rotation(infoC.rotation),
+		Outputs:   copySlice[RROutput](unsafe.Pointer(infoC.outputs), int(infoC.noutput)),
+		Rotations: Rotation(infoC.rotations),
+		Possible:  copySlice[RROutput](unsafe.Pointer(infoC.possible), int(infoC.npossible)),
+	}
+}
+
+// decodeRandREvent decodes the RandR event with the given code relative to
+// the event base. It also lets Xlib update the screen size it caches, as
+// XRRUpdateConfiguration requires for every RandR event.
+func decodeRandREvent(xeventC *C.XEvent, code int) XEvent {
+	p := unsafe.Pointer(xeventC)
+	switch code {
+	case RRScreenChangeNotify:
+		C.XRRUpdateConfiguration(xeventC)
+		e := (*C.XRRScreenChangeNotifyEvent)(p)
+		return &XRRScreenChangeNotifyEvent{
+			tEventType:      tEventType{int(e._type)},
+			Serial:          uint64(e.serial),
+			SendEvent:       e.send_event != 0,
+			Display:         (*Display)(e.display),
+			Window:          Window(e.window),
+			Root:            Window(e.root),
+			Timestamp:       uint64(e.timestamp),
+			ConfigTimestamp: uint64(e.config_timestamp),
+			Rotation:        Rotation(e.rotation),
+			Width:           int(e.width),
+			Height:          int(e.height),
+			MMWidth:         int(e.mwidth),
+			MMHeight:        int(e.mheight),
+		}
+	case RRNotify:
+		C.XRRUpdateConfiguration(xeventC)
+		switch e := (*C.XRRNotifyEvent)(p); int(e.subtype) {
+		case RRNotify_CrtcChange:
+			e := (*C.XRRCrtcChangeNotifyEvent)(p)
+			return &XRRCrtcChangeNotifyEvent{
+				tEventType: tEventType{int(e._type)},
+				Serial:     uint64(e.serial),
+				SendEvent:  e.send_event != 0,
+				Display:    (*Display)(e.display),
+				Window:     Window(e.window),
+				Crtc:       RRCrtc(e.crtc),
+				Mode:       RRMode(e.mode),
+				Rotation:   Rotation(e.rotation),
+				X:          int(e.x),
+				Y:          int(e.y),
+				Width:      uint(e.width),
+				Height:     uint(e.height),
+			}
+		case RRNotify_OutputChange:
+			e := (*C.XRROutputChangeNotifyEvent)(p)
+			return &XRROutputChangeNotifyEvent{
+				tEventType: tEventType{int(e._type)},
+				Serial:     uint64(e.serial),
+				SendEvent:  e.send_event != 0,
+				Display:    (*Display)(e.display),
+				Window:     Window(e.window),
+				Output:     RROutput(e.output),
+				Crtc:       RRCrtc(e.crtc),
+				Mode:       RRMode(e.mode),
+				Rotation:   Rotation(e.rotation),
+				Connection: int(e.connection),
+			}
+		}
+	}
+	return nil
+}
+
+// copySlice copies n elements of a C array into Go memory, so the result
+// stays valid once the array is freed.
+func copySlice[T any](data unsafe.Pointer, n int) []T {
+	if data == nil || n <= 0 {
+		return nil
+	}
+	return append([]T(nil), unsafe.Slice((*T)(data), n)...)
+}
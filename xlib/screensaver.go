@@ -0,0 +1,123 @@
+package xlib
+
+// #cgo LDFLAGS: -lXss
+// #include <X11/Xlib.h>
+// #include <X11/extensions/scrnsaver.h>
+import "C"
+import (
+	"errors"
+	"sync"
+	"unsafe"
+)
+
+const (
+	ScreenSaverNotifyMask = int64(C.ScreenSaverNotifyMask)
+	ScreenSaverCycleMask  = int64(C.ScreenSaverCycleMask)
+
+	ScreenSaverNotify = int(C.ScreenSaverNotify)
+
+	ScreenSaverOff      = int(C.ScreenSaverOff)
+	ScreenSaverOn       = int(C.ScreenSaverOn)
+	ScreenSaverCycle    = int(C.ScreenSaverCycle)
+	ScreenSaverDisabled = int(C.ScreenSaverDisabled)
+
+	ScreenSaverBlanked  = int(C.ScreenSaverBlanked)
+	ScreenSaverInternal = int(C.ScreenSaverInternal)
+	ScreenSaverExternal = int(C.ScreenSaverExternal)
+)
+
+type XScreenSaverInfo struct {
+	Window Window
+	State  int
+	Kind   int
+	// TilOrSince is the time in milliseconds until the screen saver
+	// activates, or since it did.
+	TilOrSince uint64
+	// Idle is the time in milliseconds since the last user input.
+	Idle      uint64
+	EventMask int64
+}
+
+type XScreenSaverNotifyEvent struct {
+	tEventType
+	Serial    uint64
+	SendEvent bool
+	Display   *Display
+	Window    Window
+	Root      Window
+	State     int
+	Kind      int
+	Forced    bool
+	Time      uint64
+}
+
+var screenSaverDecoder sync.Once
+
+// XScreenSaverQueryExtension reports whether the server supports the
+// MIT-SCREEN-SAVER extension. The first successful call also makes
+// XNextEvent decode its events.
+func XScreenSaverQueryExtension(display *Display) (eventBase, errorBase int, ok bool) {
+	displayC := (*C.Display)(display)
+	var eventBaseC, errorBaseC C.int
+	if C.XScreenSaverQueryExtension(displayC, &eventBaseC, &errorBaseC) == 0 {
+		return 0, 0, false
+	}
+	eventBase, errorBase = int(eventBaseC), int(errorBaseC)
+	screenSaverDecoder.Do(func() {
+		registerEventDecoder(func(xeventC *C.XEvent, typeCode int) XEvent {
+			if typeCode-eventBase != ScreenSaverNotify {
+				return nil
+			}
+			e := (*C.XScreenSaverNotifyEvent)(unsafe.Pointer(xeventC))
+			return &XScreenSaverNotifyEvent{
+				tEventType: tEventType{typeCode},
+				Serial:     uint64(e.serial),
+				SendEvent:  e.send_event != 0,
+				Display:    (*Display)(e.display),
+				Window:     Window(e.window),
+				Root:       Window(e.root),
+				State:      int(e.state),
+				Kind:       int(e.kind),
+				Forced:     e.forced != 0,
+				Time:       uint64(e.time),
+			}
+		})
+	})
+	return eventBase, errorBase, true
+}
+
+func XScreenSaverQueryVersion(display *Display) (major, minor int, ok bool) {
+	displayC := (*C.Display)(display)
+	var majorC, minorC C.int
+	if C.XScreenSaverQueryVersion(displayC, &majorC, &minorC) == 0 {
+		return 0, 0, false
+	}
+	return int(majorC), int(minorC), true
+}
+
+func XScreenSaverQueryInfo(display *Display, drawable Window) (*XScreenSaverInfo, error) {
+	displayC := (*C.Display)(display)
+	drawableC := (C.Drawable)(drawable)
+	infoC := C.XScreenSaverAllocInfo()
+	if infoC == nil {
+		return nil, errors.New("xlib: XScreenSaverAllocInfo failed")
+	}
+	defer C.XFree(unsafe.Pointer(infoC))
+	if C.XScreenSaverQueryInfo(displayC, drawableC, infoC) == 0 {
+		return nil, errors.New("xlib: XScreenSaverQueryInfo failed")
+	}
+	return &XScreenSaverInfo{
+		Window:     Window(infoC.window),
+		State:      int(infoC.state),
+		Kind:       int(infoC.kind),
+		TilOrSince: uint64(infoC.til_or_since),
+		Idle:       uint64(infoC.idle),
+		EventMask:  int64(infoC.eventMask),
+	}, nil
+}
+
+func XScreenSaverSelectInput(display *Display, window Window, eventMask int64) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XScreenSaverSelectInput(displayC, windowC, C.ulong(eventMask))
+}
@@ -0,0 +1,62 @@
+package xlib
+
+// #include <stdlib.h>
+// #include <X11/Xlib.h>
+// #include <X11/Xutil.h>
+// #include <X11/extensions/shape.h>
+import "C"
+
+type XRectangle struct {
+	X, Y          int
+	Width, Height uint
+}
+
+func (rect XRectangle) c() C.XRectangle {
+	return C.XRectangle{
+		x:      C.short(rect.X),
+		y:      C.short(rect.Y),
+		width:  C.ushort(rect.Width),
+		height: C.ushort(rect.Height),
+	}
+}
+
+func XShapeQueryExtension(display *Display) (eventBase, errorBase int, ok bool) {
+	displayC := (*C.Display)(display)
+	var eventBaseC, errorBaseC C.int
+	if C.XShapeQueryExtension(displayC, &eventBaseC, &errorBaseC) == 0 {
+		return 0, 0, false
+	}
+	return int(eventBaseC), int(errorBaseC), true
+}
+
+func XShapeQueryVersion(display *Display) (major, minor int, ok bool) {
+	displayC := (*C.Display)(display)
+	var majorC, minorC C.int
+	if C.XShapeQueryVersion(displayC, &majorC, &minorC) == 0 {
+		return 0, 0, false
+	}
+	return int(majorC), int(minorC), true
+}
+
+func XShapeCombineRectangles(display *Display, window Window, destKind, xOff, yOff int, rectangles []XRectangle, op, ordering int) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	rectanglesC := make([]C.XRectangle, max(len(rectangles), 1))
+	for i, rect := range rectangles {
+		rectanglesC[i] = rect.c()
+	}
+	C.XShapeCombineRectangles(displayC, windowC, C.int(destKind), C.int(xOff), C.int(yOff), &rectanglesC[0], C.int(len(rectangles)), C.int(op), C.int(ordering))
+}
+
+// XUnionRectWithRegion sets dest to the union of src and rect. src and dest
+// may be the same region.
+func XUnionRectWithRegion(rect XRectangle, src, dest Region) {
+	rectC := rect.c()
+	C.XUnionRectWithRegion(&rectC, src, dest)
+}
+
+func XShapeCombineMask(display *Display, window Window, destKind, xOff, yOff int, src Pixmap, op int) {
+	displayC := (*C.Display)(display)
+	windowC := (C.Window)(window)
+	C.XShapeCombineMask(displayC, windowC, C.int(destKind), C.int(xOff), C.int(yOff), C.Pixmap(src), C.int(op))
+}
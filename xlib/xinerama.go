@@ -0,0 +1,52 @@
+package xlib
+
+// #cgo LDFLAGS: -lXinerama
+// #include <X11/Xlib.h>
+// #include <X11/extensions/Xinerama.h>
+import "C"
+import (
+	"unsafe"
+)
+
+type XineramaScreenInfo struct {
+	ScreenNumber  int
+	X, Y          int
+	Width, Height uint
+}
+
+func XineramaQueryExtension(display *Display) (eventBase, errorBase int, ok bool) {
+	displayC := (*C.Display)(display)
+	var eventBaseC, errorBaseC C.int
+	if C.XineramaQueryExtension(displayC, &eventBaseC, &errorBaseC) == 0 {
+		return 0, 0, false
+	}
+	return int(eventBaseC), int(errorBaseC), true
+}
+
+func XineramaIsActive(display *Display) bool {
+	displayC := (*C.Display)(display)
+	return C.XineramaIsActive(displayC) != 0
+}
+
+// XineramaQueryScreens returns a copy of the screens Xinerama combines, or
+// nil if it is not active.
+func XineramaQueryScreens(display *Display) []XineramaScreenInfo {
+	displayC := (*C.Display)(display)
+	var nC C.int
+	screensC := C.XineramaQueryScreens(displayC, &nC)
+	if screensC == nil {
+		return nil
+	}
+	defer C.XFree(unsafe.Pointer(screensC))
+	screens := make([]XineramaScreenInfo, int(nC))
+	for i, s := range unsafe.Slice(screensC, int(nC)) {
+		screens[i] = XineramaScreenInfo{
+			ScreenNumber: int(s.screen_number),
+			X:            int(s.x_org),
+			Y:            int(s.y_org),
+			Width:        uint(s.width),
+			Height:       uint(s.height),
+		}
+	}
+	return screens
+}
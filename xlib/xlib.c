@@ -6,6 +6,7 @@
 
 
 #include <X11/Xlib.h>
+#include <X11/Xutil.h>
 
 void xlib_xevent_type ( const XEvent *const xevent, int *const xevent_type_return ) {
 	xevent_type_return[0] = xevent[0].type;
@@ -30,6 +31,7 @@ void xlib_xkeyevent_values ( const XEvent *const xevent,
 	send_event_return[0] = xevent[0].xkey.send_event;
 	display_return[0] = xevent[0].xkey.display;
 	window_return[0] = xevent[0].xkey.window;
+	root_return[0] = xevent[0].xkey.root;
 	subwindow_return[0] = xevent[0].xkey.subwindow;
 	time_return[0] = xevent[0].xkey.time;
 	x_return[0] = xevent[0].xkey.x;
@@ -40,3 +42,24 @@ void xlib_xkeyevent_values ( const XEvent *const xevent,
 	keycode_return[0] = xevent[0].xkey.keycode;
 	same_screen_return[0] = xevent[0].xkey.same_screen;
 }
+
+extern int goXErrorHandler ( Display *display, XErrorEvent *event );
+extern int goXIOErrorHandler ( Display *display );
+
+static int xlib_error_handler ( Display *const display, XErrorEvent *const event ) {
+	return goXErrorHandler(display, event);
+}
+
+static int xlib_io_error_handler ( Display *const display ) {
+	return goXIOErrorHandler(display);
+}
+
+int xlib_set_error_handlers ( void ) {
+	const XErrorHandler previous = XSetErrorHandler(xlib_error_handler);
+	const XIOErrorHandler previous_io = XSetIOErrorHandler(xlib_io_error_handler);
+	return previous != xlib_error_handler || previous_io != xlib_io_error_handler;
+}
+
+void xlib_destroy_image ( XImage *const image ) {
+	XDestroyImage(image);
+}
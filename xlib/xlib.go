@@ -10,6 +10,7 @@ package xlib
 // #include <stdlib.h>
 // #include <X11/Xlib.h>
 // #include <X11/Xutil.h>
+// #include <X11/Xresource.h>
 // #include <X11/extensions/shape.h>
 // #include "xlib.h"
 import "C"
@@ -31,6 +32,7 @@ type XSetWindowAttributes C.XSetWindowAttributes
 type Region C.Region
 type XSizeHints C.XSizeHints
 type XClassHint C.XClassHint
+type XrmDatabase C.XrmDatabase
 
 type SetWindowAttributes struct {
 	BackgroundPixmap   uint64
@@ -607,3 +609,55 @@ func XDefaultDepth(display *Display, screenNumber int) int {
 	depth := C.XDefaultDepth(displayC, screenNumberC)
 	return int(depth)
 }
+
+func XWidthMMOfScreen(screen *Screen) int {
+	screenC := (*C.Screen)(screen)
+	width := C.XWidthMMOfScreen(screenC)
+	return int(width)
+}
+
+func XHeightMMOfScreen(screen *Screen) int {
+	screenC := (*C.Screen)(screen)
+	height := C.XHeightMMOfScreen(screenC)
+	return int(height)
+}
+
+func XResourceManagerString(display *Display) string {
+	displayC := (*C.Display)(display)
+	resourcesC := C.XResourceManagerString(displayC)
+	if resourcesC == nil {
+		return ""
+	}
+	return C.GoString(resourcesC)
+}
+
+func XrmInitialize() {
+	C.XrmInitialize()
+}
+
+func XrmGetStringDatabase(data string) XrmDatabase {
+	dataC := C.CString(data)
+	database := C.XrmGetStringDatabase(dataC)
+	C.free(unsafe.Pointer(dataC))
+	return XrmDatabase(database)
+}
+
+// XrmGetResource looks up a resource by its full name and class, returning
+// its value and whether it was found.
+func XrmGetResource(database XrmDatabase, name, class string) (string, bool) {
+	databaseC := (C.XrmDatabase)(database)
+	nameC := C.CString(name)
+	defer C.free(unsafe.Pointer(nameC))
+	classC := C.CString(class)
+	defer C.free(unsafe.Pointer(classC))
+	var typeC *C.char
+	var valueC C.XrmValue
+	if C.XrmGetResource(databaseC, nameC, classC, &typeC, &valueC) == 0 || valueC.addr == nil {
+		return "", false
+	}
+	return C.GoString((*C.char)(unsafe.Pointer(valueC.addr))), true
+}
+
+func XrmDestroyDatabase(database XrmDatabase) {
+	C.XrmDestroyDatabase((C.XrmDatabase)(database))
+}
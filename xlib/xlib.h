@@ -24,4 +24,7 @@ extern void xlib_xkeyevent_values ( const XEvent *const xevent,
                                      unsigned int *const keycode_return,
                                      Bool *const same_screen_return);
 
+extern int xlib_set_error_handlers ( void );
+extern void xlib_destroy_image ( XImage *const image );
+
 #endif /* GOXLIB_H */